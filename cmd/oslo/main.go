@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
+	"os"
+	"os/signal"
 	"runtime/debug"
 	"strings"
+	"syscall"
 
 	"github.com/spf13/cobra"
 
@@ -13,8 +17,11 @@ import (
 var version string
 
 func main() {
+	// Cancel in-flight work, like HTTP requests, on Ctrl-C.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 	root := cli.NewRootCmd(getBuildVersion(version))
-	cobra.CheckErr(root.Execute())
+	cobra.CheckErr(root.ExecuteContext(ctx))
 }
 
 func getBuildVersion(version string) string {
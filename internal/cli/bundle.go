@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/OpenSLO/oslo/internal/files"
+)
+
+// NewBundleCmd returns a new command for bundling multiple files into a single manifest.
+func NewBundleCmd() *cobra.Command {
+	var (
+		fileFlags fileFlags
+		output    string
+		opts      files.BundleOptions
+	)
+
+	bundleCmd := &cobra.Command{
+		Use:   "bundle [FILE...]",
+		Short: "Bundles the provided input into a single manifest.",
+		Long: `Bundles the provided input into a single manifest.
+
+Exact duplicates are dropped and objects are ordered so that dependencies come before dependents.
+Each object records the file it was read from under the '` + files.OriginAnnotation + `' annotation.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			discoveredFilePaths, err := fileFlags.discoverFiles(cmd.Context(), args)
+			if err != nil {
+				return err
+			}
+			opts.Format, err = parseOutputFormat(output)
+			if err != nil {
+				return err
+			}
+			readOpts, err := fileFlags.readOptions()
+			if err != nil {
+				return err
+			}
+			objectsPerSource, err := files.ReadObjects(cmd.Context(), discoveredFilePaths, readOpts)
+			if err != nil {
+				return err
+			}
+			if err = fileFlags.checkDecryptedOutput(); err != nil {
+				return err
+			}
+			return files.Bundle(cmd.OutOrStdout(), discoveredFilePaths, objectsPerSource, opts)
+		},
+	}
+	registerFileRelatedFlags(bundleCmd, &fileFlags)
+	registerAllowDecryptedFlag(bundleCmd, &fileFlags)
+	registerExtractKubernetesFlags(bundleCmd, &fileFlags)
+	bundleCmd.Flags().StringVarP(
+		&output, "output", "o", "yaml",
+		"The output format, one of [json, yaml].",
+	)
+	bundleCmd.Flags().BoolVar(
+		&opts.Checksum, "checksum", false,
+		"Prepend the bundle with a SHA-256 checksum header, only supported for YAML output.",
+	)
+	return bundleCmd
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/OpenSLO/go-sdk/pkg/openslo"
+	"github.com/OpenSLO/go-sdk/pkg/openslosdk"
+
+	"github.com/OpenSLO/oslo/internal/convert"
+	"github.com/OpenSLO/oslo/internal/files"
+)
+
+// NewConvertCmd returns a new command for converting objects between OpenSLO versions.
+func NewConvertCmd() *cobra.Command {
+	var (
+		fileFlags fileFlags
+		output    string
+		toVersion string
+	)
+
+	convertCmd := &cobra.Command{
+		Use:   "convert [FILE...]",
+		Short: "Converts the provided input to another OpenSLO version.",
+		Long: `Converts the provided input to another OpenSLO version.
+
+Renamed and restructured fields are mapped between versions, for example openslo/v1 'indicator'
+becomes openslo.com/v2alpha 'sli' and labels with a list of values become labels with a single value.
+Every field which cannot be carried over without loss is reported on stderr.
+Converted objects are validated and nothing is written if any of them is invalid.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version := openslo.Version(toVersion)
+			if !slices.Contains(convert.SupportedVersions, version) {
+				return fmt.Errorf("unsupported version: %s, must be one of %v", toVersion, convert.SupportedVersions)
+			}
+			discoveredFilePaths, err := fileFlags.discoverFiles(cmd.Context(), args)
+			if err != nil {
+				return err
+			}
+			format, err := parseOutputFormat(output)
+			if err != nil {
+				return err
+			}
+			readOpts, err := fileFlags.readOptions()
+			if err != nil {
+				return err
+			}
+			objectsPerSource, err := files.ReadObjects(cmd.Context(), discoveredFilePaths, readOpts)
+			if err != nil {
+				return err
+			}
+			if err = fileFlags.checkDecryptedOutput(); err != nil {
+				return err
+			}
+
+			var converted []openslo.Object
+			hasErrors := false
+			for _, src := range slices.Sorted(maps.Keys(objectsPerSource)) {
+				for _, object := range objectsPerSource[src] {
+					result, losses, err := convert.Convert(object, version)
+					if err != nil {
+						return fmt.Errorf("failed to convert objects from %s: %w", src, err)
+					}
+					for _, loss := range losses {
+						fmt.Fprintf(cmd.ErrOrStderr(), "%s: %s: %s\n", src, object, loss)
+					}
+					if err = result.Validate(); err != nil {
+						hasErrors = true
+						printValidationError(src, result, err)
+					}
+					converted = append(converted, result)
+				}
+			}
+			if hasErrors {
+				return errors.New("Converted configuration is not valid!")
+			}
+			return openslosdk.Encode(cmd.OutOrStdout(), format, converted...)
+		},
+	}
+	registerFileRelatedFlags(convertCmd, &fileFlags)
+	registerAllowDecryptedFlag(convertCmd, &fileFlags)
+	convertCmd.Flags().StringVarP(
+		&output, "output", "o", "yaml",
+		"The output format, one of [json, yaml].",
+	)
+	convertCmd.Flags().StringVar(
+		&toVersion, "to-version", "",
+		fmt.Sprintf("The version to convert objects to, one of %v.", convert.SupportedVersions),
+	)
+	_ = convertCmd.MarkFlagRequired("to-version")
+	return convertCmd
+}
@@ -1,20 +1,403 @@
 package cli
 
-import "github.com/spf13/cobra"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/OpenSLO/oslo/internal/config"
+	"github.com/OpenSLO/oslo/internal/files"
+	"github.com/OpenSLO/oslo/internal/render"
+)
+
+// httpTokenEnv is the environment variable used as the bearer token when --http-token is not set.
+const httpTokenEnv = "OSLO_HTTP_TOKEN"
+
+// fileFlags holds values of flags related to discovering and reading files.
+type fileFlags struct {
+	cmd         *cobra.Command
+	filePaths   []string
+	discover    files.DiscoverOptions
+	read        files.ReadOptions
+	httpHeaders []string
+	lockFile    string
+	lock        *files.Lock
+	session     *files.Session
+	inputFormat string
+	valuesFiles []string
+	render      bool
+	extStrs     []string
+	extCodes    []string
+
+	allowDecrypted bool
+}
 
 // registerFileRelatedFlags registers flags --file | -f and --recursive | -R for command
-// passed as the argument and make them required.
-func registerFileRelatedFlags(cmd *cobra.Command, filePaths *[]string, recursive *bool) {
-	const fileFlag = "file"
+// passed as the argument.
+// It also registers flags which control how files are discovered in directories and downloaded from URLs.
+func registerFileRelatedFlags(cmd *cobra.Command, f *fileFlags) {
+	f.cmd = cmd
 	cmd.Flags().StringArrayVarP(
-		filePaths, fileFlag, "f", []string{},
-		"The file(s) that contain the configurations.",
+		&f.filePaths, "file", "f", []string{},
+		"The file(s) that contain the configurations. They can also be passed as arguments.",
 	)
-	if err := cmd.MarkFlagRequired(fileFlag); err != nil {
-		panic(err)
-	}
 	cmd.Flags().BoolVarP(
-		recursive, "recursive", "R", false,
+		&f.discover.Recursive, "recursive", "R", false,
 		"Process the directory used in -f, --filename recursively. Useful when you want to manage related manifests organized within the same directory.", //nolint:lll
 	)
+	cmd.Flags().StringArrayVar(
+		&f.discover.Include, "include", []string{},
+		includeFlagUsage(),
+	)
+	cmd.Flags().StringArrayVar(
+		&f.discover.Exclude, "exclude", []string{},
+		"Glob pattern(s) of files and directories to skip in directories.",
+	)
+	cmd.Flags().BoolVar(
+		&f.discover.Hidden, "hidden", false,
+		"Read files and traverse directories whose names start with a dot.",
+	)
+	cmd.Flags().BoolVar(
+		&f.discover.GitIgnore, "gitignore", false,
+		"Skip files and directories listed in .gitignore files, in addition to .osloignore files.",
+	)
+	cmd.Flags().BoolVar(
+		&f.discover.FollowSymlinks, "follow-symlinks", false,
+		"Traverse symbolic links to directories when processing directories recursively.",
+	)
+	cmd.Flags().StringVar(
+		&f.inputFormat, "input-format", "auto",
+		"The format of the input, one of [auto, yaml, json, ndjson, jsonnet, cue]. "+
+			"When auto, it's detected from the file extension, the HTTP Content-Type header and the content.",
+	)
+	cmd.Flags().StringArrayVar(
+		&f.read.Jsonnet.JPaths, "jpath", []string{},
+		"The directory(ies) searched for files imported by Jsonnet sources.",
+	)
+	cmd.Flags().StringArrayVar(
+		&f.extStrs, "ext-str", []string{},
+		"The external string variable(s) of Jsonnet sources, in 'name=value' format. "+
+			"The value is read from the environment variable of the same name if omitted.",
+	)
+	cmd.Flags().StringArrayVar(
+		&f.extCodes, "ext-code", []string{},
+		"The external variable(s) of Jsonnet sources with Jsonnet code as their values, in 'name=code' format.",
+	)
+	cmd.Flags().BoolVar(
+		&f.read.ExpandEnv, "expand-env", false,
+		"Replace ${VAR} and ${VAR:-default} references with environment variables before decoding, "+
+			"use $$ for a literal $.",
+	)
+	cmd.Flags().BoolVar(
+		&f.read.ExpandEnvStrict, "expand-env-strict", false,
+		"Fail if a variable referenced in the input is not set and has no default, implies --expand-env.",
+	)
+	cmd.Flags().DurationVar(
+		&f.read.HTTP.Timeout, "http-timeout", 30*time.Second,
+		"The timeout of a single HTTP request made to download a file, 0 means no timeout.",
+	)
+	cmd.Flags().IntVar(
+		&f.read.HTTP.Retries, "http-retries", 3,
+		"The number of times an HTTP request is retried after a network error or a 5xx response.",
+	)
+	cmd.Flags().StringArrayVar(
+		&f.httpHeaders, "http-header", []string{},
+		"The header(s) added to HTTP requests, in 'Name: value' format.",
+	)
+	cmd.Flags().StringVar(
+		&f.read.HTTP.BearerToken, "http-token", "",
+		"The bearer token sent with HTTP requests. Defaults to the value of "+httpTokenEnv+" environment variable.",
+	)
+	cmd.Flags().StringVar(
+		&f.read.HTTP.CAFile, "http-ca-file", "",
+		"The PEM encoded CA bundle used to verify HTTPS servers, in addition to the system certificates.",
+	)
+	cmd.Flags().StringVar(
+		&f.read.HTTP.CertFile, "http-cert-file", "",
+		"The PEM encoded client certificate used for mutual TLS, requires --http-key-file.",
+	)
+	cmd.Flags().StringVar(
+		&f.read.HTTP.KeyFile, "http-key-file", "",
+		"The PEM encoded private key of the client certificate.",
+	)
+	cmd.Flags().StringVar(
+		&f.read.HTTP.Proxy, "http-proxy", "",
+		"The proxy URL used for HTTP requests. Defaults to HTTP_PROXY and HTTPS_PROXY environment variables.",
+	)
+	cmd.Flags().StringVar(
+		&f.read.HTTP.NoProxy, "http-no-proxy", "",
+		"Comma separated list of hosts which are not reached through --http-proxy.",
+	)
+	cmd.Flags().BoolVar(
+		&f.read.HTTP.InsecureSkipVerify, "http-insecure-skip-verify", false,
+		"Skip verification of HTTPS server certificates. Insecure, use only for testing.",
+	)
+	cmd.Flags().BoolVar(
+		&f.read.HTTP.Cache, "http-cache", true,
+		"Cache downloaded files and revalidate them with the server using ETag and Last-Modified headers.",
+	)
+	cmd.Flags().BoolVar(
+		&f.read.HTTP.CacheAuthenticated, "http-cache-authenticated", false,
+		"Also cache files downloaded with --http-token or --http-header, separately for every set of them.",
+	)
+	cmd.Flags().BoolVar(
+		&f.read.Offline, "offline", false,
+		"Never access the network, serve URL and git sources only from the cache.",
+	)
+	cmd.Flags().StringArrayVar(
+		&f.read.AllowedHosts, "allowed-host", []string{},
+		"The only host(s) URL and git sources can be fetched from, e.g. example.com or *.example.com.",
+	)
+	cmd.Flags().StringArrayVar(
+		&f.read.DeniedHosts, "denied-host", []string{},
+		"The host(s) URL and git sources must not be fetched from, takes precedence over --allowed-host.",
+	)
+	cmd.Flags().Int64Var(
+		&f.read.HTTP.MaxResponseSize, "http-max-response-size", 0,
+		"The maximum size in bytes of a downloaded file, 0 means no limit.",
+	)
+	cmd.Flags().Int64Var(
+		&f.read.MaxFileSize, "max-file-size", 0,
+		"The maximum size in bytes of a local file, standard input or a file in an archive, 0 means no limit.",
+	)
+	cmd.Flags().Int64Var(
+		&f.read.MaxArchiveSize, "max-archive-size", files.DefaultMaxArchiveSize,
+		"The maximum total size in bytes of all files extracted from a single archive.",
+	)
+	cmd.Flags().BoolVar(
+		&f.read.Locked, "locked", false,
+		"Fail if a URL or git source is not recorded in the lock file or its checksum differs.",
+	)
+	cmd.Flags().StringVar(
+		&f.lockFile, "lock-file", files.LockFileName,
+		"The path to the lock file, created with 'oslo lock update'.",
+	)
+}
+
+// registerValuesFlag registers --values flag, which enables rendering sources as templates.
+func registerValuesFlag(cmd *cobra.Command, f *fileFlags) {
+	cmd.Flags().StringArrayVar(
+		&f.valuesFiles, "values", []string{},
+		"The YAML file(s) with values for sources written as Go templates, later files override earlier ones. "+
+			"Sources are rendered only if at least one values file is provided.",
+	)
+}
+
+// registerAllowDecryptedFlag registers --allow-decrypted flag for commands writing the content of sources,
+// see [fileFlags.checkDecryptedOutput].
+func registerAllowDecryptedFlag(cmd *cobra.Command, f *fileFlags) {
+	cmd.Flags().BoolVar(
+		&f.allowDecrypted, "allow-decrypted", false,
+		"Allow writing the decrypted content of SOPS encrypted sources in plaintext.",
+	)
+}
+
+// registerExtractKubernetesFlags registers flags which enable reading objects embedded in Kubernetes resources.
+func registerExtractKubernetesFlags(cmd *cobra.Command, f *fileFlags) {
+	cmd.Flags().BoolVar(
+		&f.read.ExtractKubernetes, "extract-kubernetes", false,
+		"Extract objects embedded in Kubernetes resources, like ConfigMaps and custom resources, "+
+			"and skip other resources.",
+	)
+	cmd.Flags().StringArrayVar(
+		&f.read.KubernetesGroups, "kubernetes-group", []string{},
+		"The API group(s) of custom resources wrapping OpenSLO objects, in addition to groups containing 'openslo'. "+
+			"Implies --extract-kubernetes.",
+	)
+}
+
+// readOptions returns [files.ReadOptions] built from the flags.
+// Values from the config file are used for the flags which were not set explicitly.
+func (f *fileFlags) readOptions() (files.ReadOptions, error) {
+	conf, err := loadConfig(f.cmd)
+	if err != nil {
+		return files.ReadOptions{}, err
+	}
+	opts := f.read
+	if opts.Jsonnet.ExtVars, err = parseJsonnetVars(f.extStrs, "--ext-str"); err != nil {
+		return files.ReadOptions{}, err
+	}
+	if opts.Jsonnet.ExtCodes, err = parseJsonnetVars(f.extCodes, "--ext-code"); err != nil {
+		return files.ReadOptions{}, err
+	}
+	if f.render || len(f.valuesFiles) > 0 {
+		opts.Render = true
+		if opts.Values, err = render.LoadValues(f.valuesFiles); err != nil {
+			return files.ReadOptions{}, err
+		}
+	}
+	if opts.ExpandEnvStrict {
+		opts.ExpandEnv = true
+	}
+	if len(opts.KubernetesGroups) > 0 {
+		opts.ExtractKubernetes = true
+	}
+	if opts.InputFormat, err = files.ParseInputFormat(f.inputFormat); err != nil {
+		return files.ReadOptions{}, err
+	}
+	f.applyHTTPConfig(&opts.HTTP, conf.HTTP)
+	if len(conf.HTTP.AllowedHosts) > 0 && !f.changed("allowed-host") {
+		opts.AllowedHosts = conf.HTTP.AllowedHosts
+	}
+	if len(conf.HTTP.DeniedHosts) > 0 && !f.changed("denied-host") {
+		opts.DeniedHosts = conf.HTTP.DeniedHosts
+	}
+	if conf.MaxFileSize != nil && !f.changed("max-file-size") {
+		opts.MaxFileSize = *conf.MaxFileSize
+	}
+	if conf.MaxArchiveSize != nil && !f.changed("max-archive-size") {
+		opts.MaxArchiveSize = *conf.MaxArchiveSize
+	}
+	if opts.HTTP.BearerToken == "" {
+		opts.HTTP.BearerToken = os.Getenv(httpTokenEnv)
+	}
+	headers, err := parseHTTPHeaders(f.httpHeaders)
+	if err != nil {
+		return files.ReadOptions{}, err
+	}
+	for name, value := range conf.HTTP.Headers {
+		name = textproto.CanonicalMIMEHeaderKey(name)
+		if _, ok := headers[name]; !ok {
+			headers.Set(name, value)
+		}
+	}
+	opts.HTTP.Headers = headers
+	if opts.Locked && f.lock == nil {
+		if f.lock, err = files.LoadLock(f.lockFile); err != nil {
+			return files.ReadOptions{}, err
+		}
+	}
+	opts.Lock = f.lock
+	if f.session == nil {
+		f.session = files.NewSession()
+	}
+	opts.Session = f.session
+	return opts, nil
+}
+
+// checkDecryptedOutput returns [files.ErrDecryptedOutput] if any of the read sources was decrypted with SOPS,
+// unless --allow-decrypted is set. It must be called after the sources are read, but before any output is written.
+func (f *fileFlags) checkDecryptedOutput() error {
+	if f.allowDecrypted || f.session == nil {
+		return nil
+	}
+	if decrypted := f.session.Decrypted(); len(decrypted) > 0 {
+		return decryptedOutputError(fmt.Errorf("%s is encrypted with SOPS: %w", decrypted[0], files.ErrDecryptedOutput))
+	}
+	return nil
+}
+
+// decryptedOutputError hints how to write the decrypted content if the error is [files.ErrDecryptedOutput].
+func decryptedOutputError(err error) error {
+	if errors.Is(err, files.ErrDecryptedOutput) {
+		return fmt.Errorf("%w, pass --allow-decrypted to write it anyway", err)
+	}
+	return err
+}
+
+// includeFlagUsage returns the usage of --include flag, which lists [files.DefaultExtensions].
+func includeFlagUsage() string {
+	exts := files.DefaultExtensions
+	return fmt.Sprintf(
+		"Glob pattern(s) of files to read from directories. Defaults to files with %s and %s extensions.",
+		strings.Join(exts[:len(exts)-1], ", "), exts[len(exts)-1],
+	)
+}
+
+// changed reports whether the flag was set explicitly.
+func (f *fileFlags) changed(name string) bool {
+	return f.cmd != nil && f.cmd.Flags().Changed(name)
+}
+
+func (f *fileFlags) applyHTTPConfig(opts *files.HTTPOptions, conf config.HTTP) {
+	if conf.Timeout != nil && !f.changed("http-timeout") {
+		opts.Timeout = conf.Timeout.Duration
+	}
+	if conf.Retries != nil && !f.changed("http-retries") {
+		opts.Retries = *conf.Retries
+	}
+	if conf.InsecureSkipVerify != nil && !f.changed("http-insecure-skip-verify") {
+		opts.InsecureSkipVerify = *conf.InsecureSkipVerify
+	}
+	if conf.Cache != nil && !f.changed("http-cache") {
+		opts.Cache = *conf.Cache
+	}
+	if conf.CacheAuthenticated != nil && !f.changed("http-cache-authenticated") {
+		opts.CacheAuthenticated = *conf.CacheAuthenticated
+	}
+	if conf.MaxResponseSize != nil && !f.changed("http-max-response-size") {
+		opts.MaxResponseSize = *conf.MaxResponseSize
+	}
+	for _, v := range []struct {
+		flag  string
+		value string
+		dst   *string
+	}{
+		{"http-token", conf.Token, &opts.BearerToken},
+		{"http-ca-file", conf.CAFile, &opts.CAFile},
+		{"http-cert-file", conf.CertFile, &opts.CertFile},
+		{"http-key-file", conf.KeyFile, &opts.KeyFile},
+		{"http-proxy", conf.Proxy, &opts.Proxy},
+		{"http-no-proxy", conf.NoProxy, &opts.NoProxy},
+	} {
+		if v.value != "" && !f.changed(v.flag) {
+			*v.dst = v.value
+		}
+	}
+}
+
+// discoverFiles discovers files from both --file flag values and positional arguments.
+// At least one of them must be provided.
+func (f *fileFlags) discoverFiles(ctx context.Context, args []string) ([]string, error) {
+	sources := slices.Concat(f.filePaths, args)
+	if len(sources) == 0 {
+		return nil, errors.New("at least one file must be provided with --file flag or as an argument")
+	}
+	readOpts, err := f.readOptions()
+	if err != nil {
+		return nil, err
+	}
+	opts := f.discover
+	opts.Read = readOpts
+	return files.Discover(ctx, sources, opts)
+}
+
+func parseHTTPHeaders(values []string) (http.Header, error) {
+	headers := make(http.Header, len(values))
+	for _, v := range values {
+		name, value, ok := strings.Cut(v, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid HTTP header %q, expected 'Name: value' format", v)
+		}
+		headers.Add(textproto.CanonicalMIMEHeaderKey(name), strings.TrimSpace(value))
+	}
+	return headers, nil
+}
+
+// parseJsonnetVars parses external variables in 'name=value' format.
+// If the value is omitted, it's read from the environment variable of the same name.
+func parseJsonnetVars(values []string, flag string) (map[string]string, error) {
+	vars := make(map[string]string, len(values))
+	for _, v := range values {
+		name, value, ok := strings.Cut(v, "=")
+		if name == "" {
+			return nil, fmt.Errorf("invalid %s value %q, expected 'name=value' format", flag, v)
+		}
+		if !ok {
+			if value, ok = os.LookupEnv(name); !ok {
+				return nil, fmt.Errorf("invalid %s value %q: environment variable %s is not set", flag, v, name)
+			}
+		}
+		vars[name] = value
+	}
+	return vars, nil
 }
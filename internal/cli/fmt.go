@@ -12,36 +12,65 @@ import (
 // NewFmtCmd returns a new command for formatting a file.
 func NewFmtCmd() *cobra.Command {
 	var (
-		passedFilePaths []string
-		recursive       bool
-		output          string
+		fileFlags fileFlags
+		output    string
+		normalize bool
+		stream    bool
 	)
 
 	fmtCmd := &cobra.Command{
-		Use:   "fmt",
+		Use:   "fmt [FILE...]",
 		Short: "Formats the provided input into the standard format.",
-		Args:  cobra.ExactArgs(0),
+		Args:  cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			discoveredFilePaths, err := files.Discover(passedFilePaths, recursive)
+			discoveredFilePaths, err := fileFlags.discoverFiles(cmd.Context(), args)
 			if err != nil {
 				return err
 			}
-			var format openslosdk.ObjectFormat
-			switch output {
-			case "json":
-				format = openslosdk.FormatJSON
-			case "yaml":
-				format = openslosdk.FormatYAML
-			default:
-				return fmt.Errorf("invalid output format: %s", output)
+			format, err := parseOutputFormat(output)
+			if err != nil {
+				return err
+			}
+			readOpts, err := fileFlags.readOptions()
+			if err != nil {
+				return err
 			}
-			return files.Format(cmd.OutOrStdout(), format, discoveredFilePaths)
+			err = files.Format(cmd.Context(), cmd.OutOrStdout(), format, discoveredFilePaths, files.FormatOptions{
+				Read:           readOpts,
+				Normalize:      normalize,
+				Report:         cmd.ErrOrStderr(),
+				AllowDecrypted: fileFlags.allowDecrypted,
+				Stream:         stream,
+			})
+			return decryptedOutputError(err)
 		},
 	}
-	registerFileRelatedFlags(fmtCmd, &passedFilePaths, &recursive)
+	registerFileRelatedFlags(fmtCmd, &fileFlags)
+	registerValuesFlag(fmtCmd, &fileFlags)
+	registerAllowDecryptedFlag(fmtCmd, &fileFlags)
 	fmtCmd.Flags().StringVarP(
 		&output, "output", "o", "yaml",
 		"The output format, one of [json, yaml].",
 	)
+	fmtCmd.Flags().BoolVar(
+		&normalize, "normalize", false,
+		"Rewrite durations, numbers and times to their canonical representation and report every rewrite.",
+	)
+	fmtCmd.Flags().BoolVar(
+		&stream, "stream", false,
+		"Read, decode and format one document at a time, to format large files with bounded memory.",
+	)
 	return fmtCmd
 }
+
+// parseOutputFormat converts the value of --output flag to [openslosdk.ObjectFormat].
+func parseOutputFormat(output string) (openslosdk.ObjectFormat, error) {
+	switch output {
+	case "json":
+		return openslosdk.FormatJSON, nil
+	case "yaml":
+		return openslosdk.FormatYAML, nil
+	default:
+		return 0, fmt.Errorf("invalid output format: %s", output)
+	}
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/OpenSLO/go-sdk/pkg/openslo"
+
+	"github.com/OpenSLO/oslo/internal/files"
+	"github.com/OpenSLO/oslo/internal/prometheus"
+)
+
+// NewGenerateCmd returns a new command for generating monitoring configuration from OpenSLO objects.
+func NewGenerateCmd() *cobra.Command {
+	generateCmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generates monitoring configuration from OpenSLO objects.",
+	}
+	generateCmd.AddCommand(newGeneratePrometheusCmd())
+	return generateCmd
+}
+
+func newGeneratePrometheusCmd() *cobra.Command {
+	var fileFlags fileFlags
+
+	prometheusCmd := &cobra.Command{
+		Use:   "prometheus [FILE...]",
+		Short: "Generates Prometheus recording and alerting rules for SLOs backed by Prometheus.",
+		Long: `Generates Prometheus recording and alerting rules for SLOs backed by Prometheus.
+
+Rules are generated for every Occurrences SLO whose metric sources are of the Prometheus type,
+either inline or through a referenced DataSource. The PromQL query is read from the 'query' key
+of the metric source spec, counters must either be plain vector selectors or use the {{.window}}
+placeholder which is replaced with the evaluated window, e.g. sum(rate(http_requests_total[{{.window}}])).
+
+For every objective three rule groups are written:
+  - error ratio of the SLI recorded over 5m and every alert window,
+  - objective, error budget and remaining error budget over the SLO time window,
+  - multi-window multi-burn-rate alerts built from the SLO's AlertPolicies burnrate conditions,
+    or default page and ticket alerts if the SLO has no AlertPolicies.
+
+SLOs which cannot be expressed as rules are reported on stderr.
+The output is a Prometheus rule file which can be checked with: promtool check rules`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			discoveredFilePaths, err := fileFlags.discoverFiles(cmd.Context(), args)
+			if err != nil {
+				return err
+			}
+			readOpts, err := fileFlags.readOptions()
+			if err != nil {
+				return err
+			}
+			objectsPerSource, err := files.ReadObjects(cmd.Context(), discoveredFilePaths, readOpts)
+			if err != nil {
+				return err
+			}
+			if err = fileFlags.checkDecryptedOutput(); err != nil {
+				return err
+			}
+
+			var objects []openslo.Object
+			hasErrors := false
+			for _, src := range slices.Sorted(maps.Keys(objectsPerSource)) {
+				for _, object := range objectsPerSource[src] {
+					if err = object.Validate(); err != nil {
+						hasErrors = true
+						printValidationError(src, object, err)
+					}
+					objects = append(objects, object)
+				}
+			}
+			if hasErrors {
+				return errors.New("Configuration is not valid!")
+			}
+			ruleFile, warnings, err := prometheus.Generate(objects)
+			if err != nil {
+				return err
+			}
+			for _, warning := range warnings {
+				fmt.Fprintln(cmd.ErrOrStderr(), warning)
+			}
+			return ruleFile.Encode(cmd.OutOrStdout())
+		},
+	}
+	registerFileRelatedFlags(prometheusCmd, &fileFlags)
+	registerAllowDecryptedFlag(prometheusCmd, &fileFlags)
+	return prometheusCmd
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/OpenSLO/oslo/internal/files"
+)
+
+// NewLockCmd returns a new command for managing the lock file.
+func NewLockCmd() *cobra.Command {
+	lockCmd := &cobra.Command{
+		Use:   "lock",
+		Short: "Manages the lock file with checksums of remote sources.",
+		Long: `Manages the lock file with checksums of remote sources.
+
+The lock file records the checksum of every URL source and the resolved commit and checksum of every git source.
+Pass --locked to other commands to fail when a remote source no longer matches the lock file.`,
+	}
+	lockCmd.AddCommand(newLockUpdateCmd())
+	return lockCmd
+}
+
+func newLockUpdateCmd() *cobra.Command {
+	var fileFlags fileFlags
+
+	updateCmd := &cobra.Command{
+		Use:   "update [FILE...]",
+		Short: "Reads the provided input and records all remote sources in the lock file.",
+		Args:  cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if fileFlags.read.Locked {
+				return fmt.Errorf("--locked cannot be used with %s", cmd.CommandPath())
+			}
+			fileFlags.lock = files.NewLock()
+			discoveredFilePaths, err := fileFlags.discoverFiles(cmd.Context(), args)
+			if err != nil {
+				return err
+			}
+			readOpts, err := fileFlags.readOptions()
+			if err != nil {
+				return err
+			}
+			if _, err = files.ReadObjects(cmd.Context(), discoveredFilePaths, readOpts); err != nil {
+				return err
+			}
+			if err = fileFlags.lock.Save(fileFlags.lockFile); err != nil {
+				return fmt.Errorf("failed to write lock file: %w", err)
+			}
+			for _, src := range fileFlags.lock.Sources() {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s sha256:%s\n", src.Source, src.SHA256)
+			}
+			return nil
+		},
+	}
+	registerFileRelatedFlags(updateCmd, &fileFlags)
+	return updateCmd
+}
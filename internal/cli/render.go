@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"bytes"
+
+	"github.com/spf13/cobra"
+
+	"github.com/OpenSLO/oslo/internal/files"
+)
+
+// NewRenderCmd returns a new command for rendering sources written as Go templates.
+func NewRenderCmd() *cobra.Command {
+	fileFlags := fileFlags{render: true}
+
+	renderCmd := &cobra.Command{
+		Use:   "render [FILE...]",
+		Short: "Renders the provided templates with values files.",
+		Long: `Renders the provided templates with values files.
+
+Sources are rendered as Go templates, values are available under .Values
+and helpers known from Helm, like default, required, quote, indent, toYaml and toJson, can be used.
+Pass --values to validate and fmt commands to render sources before processing them.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			discoveredFilePaths, err := fileFlags.discoverFiles(cmd.Context(), args)
+			if err != nil {
+				return err
+			}
+			readOpts, err := fileFlags.readOptions()
+			if err != nil {
+				return err
+			}
+			// Rendered content is buffered, so that nothing is written if any of the sources was decrypted.
+			buf := new(bytes.Buffer)
+			if err = files.Render(cmd.Context(), buf, discoveredFilePaths, readOpts); err != nil {
+				return err
+			}
+			if err = fileFlags.checkDecryptedOutput(); err != nil {
+				return err
+			}
+			_, err = buf.WriteTo(cmd.OutOrStdout())
+			return err
+		},
+	}
+	registerFileRelatedFlags(renderCmd, &fileFlags)
+	registerValuesFlag(renderCmd, &fileFlags)
+	registerAllowDecryptedFlag(renderCmd, &fileFlags)
+	return renderCmd
+}
@@ -2,8 +2,12 @@ package cli
 
 import (
 	"github.com/spf13/cobra"
+
+	"github.com/OpenSLO/oslo/internal/config"
 )
 
+const configFlag = "config"
+
 func NewRootCmd(version string) *cobra.Command {
 	rootCmd := &cobra.Command{
 		Use:           "oslo",
@@ -13,6 +17,12 @@ func NewRootCmd(version string) *cobra.Command {
 		Version:       version,
 	}
 
+	rootCmd.PersistentFlags().String(
+		configFlag, "",
+		"The path to the oslo config file. Defaults to the value of "+config.Env+
+			" environment variable or oslo/config.yaml in the user config directory.",
+	)
+
 	coreGroup := &cobra.Group{
 		ID:    "core",
 		Title: "Core commands:",
@@ -22,6 +32,13 @@ func NewRootCmd(version string) *cobra.Command {
 	subCommands := []*cobra.Command{
 		NewValidateCmd(),
 		NewFmtCmd(),
+		NewConvertCmd(),
+		NewGenerateCmd(),
+		NewSplitCmd(),
+		NewBundleCmd(),
+		NewRenderCmd(),
+		NewLockCmd(),
+		NewSchemaCmd(),
 	}
 	for _, subCmd := range subCommands {
 		subCmd.GroupID = coreGroup.ID
@@ -30,3 +47,14 @@ func NewRootCmd(version string) *cobra.Command {
 
 	return rootCmd
 }
+
+// loadConfig loads the config file pointed to by the --config flag.
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
+	var path string
+	if cmd != nil {
+		if flag := cmd.Flag(configFlag); flag != nil {
+			path = flag.Value.String()
+		}
+	}
+	return config.Load(path)
+}
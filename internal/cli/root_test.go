@@ -22,18 +22,62 @@ func TestNewRootCmd(t *testing.T) {
 			wantOut: "",
 			wantErr: true,
 		},
+		{
+			name:    "validate without files",
+			args:    []string{"validate"},
+			wantOut: "",
+			wantErr: true,
+		},
 		{
 			name: "validate command exists",
 			args: []string{"validate", "--help"},
 			wantOut: `Validates your yaml file against the OpenSLO spec.
 
 Usage:
-  oslo validate [flags]
+  oslo validate [FILE...] [flags]
 
 Flags:
-  -f, --file stringArray   The file(s) that contain the configurations.
-  -h, --help               help for validate
-  -R, --recursive          Process the directory used in -f, --filename recursively. Useful when you want to manage related manifests organized within the same directory.
+      --allowed-host stringArray       The only host(s) URL and git sources can be fetched from, e.g. example.com or *.example.com.
+      --denied-host stringArray        The host(s) URL and git sources must not be fetched from, takes precedence over --allowed-host.
+      --exclude stringArray            Glob pattern(s) of files and directories to skip in directories.
+      --expand-env                     Replace ${VAR} and ${VAR:-default} references with environment variables before decoding, use $$ for a literal $.
+      --expand-env-strict              Fail if a variable referenced in the input is not set and has no default, implies --expand-env.
+      --ext-code stringArray           The external variable(s) of Jsonnet sources with Jsonnet code as their values, in 'name=code' format.
+      --ext-str stringArray            The external string variable(s) of Jsonnet sources, in 'name=value' format. The value is read from the environment variable of the same name if omitted.
+      --extract-kubernetes             Extract objects embedded in Kubernetes resources, like ConfigMaps and custom resources, and skip other resources.
+  -f, --file stringArray               The file(s) that contain the configurations. They can also be passed as arguments.
+      --follow-symlinks                Traverse symbolic links to directories when processing directories recursively.
+      --gitignore                      Skip files and directories listed in .gitignore files, in addition to .osloignore files.
+  -h, --help                           help for validate
+      --hidden                         Read files and traverse directories whose names start with a dot.
+      --http-ca-file string            The PEM encoded CA bundle used to verify HTTPS servers, in addition to the system certificates.
+      --http-cache                     Cache downloaded files and revalidate them with the server using ETag and Last-Modified headers. (default true)
+      --http-cache-authenticated       Also cache files downloaded with --http-token or --http-header, separately for every set of them.
+      --http-cert-file string          The PEM encoded client certificate used for mutual TLS, requires --http-key-file.
+      --http-header stringArray        The header(s) added to HTTP requests, in 'Name: value' format.
+      --http-insecure-skip-verify      Skip verification of HTTPS server certificates. Insecure, use only for testing.
+      --http-key-file string           The PEM encoded private key of the client certificate.
+      --http-max-response-size int     The maximum size in bytes of a downloaded file, 0 means no limit.
+      --http-no-proxy string           Comma separated list of hosts which are not reached through --http-proxy.
+      --http-proxy string              The proxy URL used for HTTP requests. Defaults to HTTP_PROXY and HTTPS_PROXY environment variables.
+      --http-retries int               The number of times an HTTP request is retried after a network error or a 5xx response. (default 3)
+      --http-timeout duration          The timeout of a single HTTP request made to download a file, 0 means no timeout. (default 30s)
+      --http-token string              The bearer token sent with HTTP requests. Defaults to the value of OSLO_HTTP_TOKEN environment variable.
+      --include stringArray            Glob pattern(s) of files to read from directories. Defaults to files with .yaml, .yml, .json, .ndjson, .jsonl, .jsonnet and .cue extensions.
+      --input-format string            The format of the input, one of [auto, yaml, json, ndjson, jsonnet, cue]. When auto, it's detected from the file extension, the HTTP Content-Type header and the content. (default "auto")
+      --jpath stringArray              The directory(ies) searched for files imported by Jsonnet sources.
+      --kubernetes-group stringArray   The API group(s) of custom resources wrapping OpenSLO objects, in addition to groups containing 'openslo'. Implies --extract-kubernetes.
+      --lock-file string               The path to the lock file, created with 'oslo lock update'. (default "oslo.lock")
+      --locked                         Fail if a URL or git source is not recorded in the lock file or its checksum differs.
+      --max-archive-size int           The maximum total size in bytes of all files extracted from a single archive. (default 268435456)
+      --max-file-size int              The maximum size in bytes of a local file, standard input or a file in an archive, 0 means no limit.
+      --offline                        Never access the network, serve URL and git sources only from the cache.
+  -R, --recursive                      Process the directory used in -f, --filename recursively. Useful when you want to manage related manifests organized within the same directory.
+      --stream                         Read, decode and validate one document at a time, to validate large files with bounded memory.
+      --values stringArray             The YAML file(s) with values for sources written as Go templates, later files override earlier ones. Sources are rendered only if at least one values file is provided.
+
+Global Flags:
+      --config string   The path to the oslo config file. Defaults to the value of OSLO_CONFIG environment variable or oslo/config.yaml in the user config directory.
 `,
 			wantErr: false,
 		},
@@ -43,13 +87,110 @@ Flags:
 			wantOut: `Formats the provided input into the standard format.
 
 Usage:
-  oslo fmt [flags]
+  oslo fmt [FILE...] [flags]
+
+Flags:
+      --allow-decrypted              Allow writing the decrypted content of SOPS encrypted sources in plaintext.
+      --allowed-host stringArray     The only host(s) URL and git sources can be fetched from, e.g. example.com or *.example.com.
+      --denied-host stringArray      The host(s) URL and git sources must not be fetched from, takes precedence over --allowed-host.
+      --exclude stringArray          Glob pattern(s) of files and directories to skip in directories.
+      --expand-env                   Replace ${VAR} and ${VAR:-default} references with environment variables before decoding, use $$ for a literal $.
+      --expand-env-strict            Fail if a variable referenced in the input is not set and has no default, implies --expand-env.
+      --ext-code stringArray         The external variable(s) of Jsonnet sources with Jsonnet code as their values, in 'name=code' format.
+      --ext-str stringArray          The external string variable(s) of Jsonnet sources, in 'name=value' format. The value is read from the environment variable of the same name if omitted.
+  -f, --file stringArray             The file(s) that contain the configurations. They can also be passed as arguments.
+      --follow-symlinks              Traverse symbolic links to directories when processing directories recursively.
+      --gitignore                    Skip files and directories listed in .gitignore files, in addition to .osloignore files.
+  -h, --help                         help for fmt
+      --hidden                       Read files and traverse directories whose names start with a dot.
+      --http-ca-file string          The PEM encoded CA bundle used to verify HTTPS servers, in addition to the system certificates.
+      --http-cache                   Cache downloaded files and revalidate them with the server using ETag and Last-Modified headers. (default true)
+      --http-cache-authenticated     Also cache files downloaded with --http-token or --http-header, separately for every set of them.
+      --http-cert-file string        The PEM encoded client certificate used for mutual TLS, requires --http-key-file.
+      --http-header stringArray      The header(s) added to HTTP requests, in 'Name: value' format.
+      --http-insecure-skip-verify    Skip verification of HTTPS server certificates. Insecure, use only for testing.
+      --http-key-file string         The PEM encoded private key of the client certificate.
+      --http-max-response-size int   The maximum size in bytes of a downloaded file, 0 means no limit.
+      --http-no-proxy string         Comma separated list of hosts which are not reached through --http-proxy.
+      --http-proxy string            The proxy URL used for HTTP requests. Defaults to HTTP_PROXY and HTTPS_PROXY environment variables.
+      --http-retries int             The number of times an HTTP request is retried after a network error or a 5xx response. (default 3)
+      --http-timeout duration        The timeout of a single HTTP request made to download a file, 0 means no timeout. (default 30s)
+      --http-token string            The bearer token sent with HTTP requests. Defaults to the value of OSLO_HTTP_TOKEN environment variable.
+      --include stringArray          Glob pattern(s) of files to read from directories. Defaults to files with .yaml, .yml, .json, .ndjson, .jsonl, .jsonnet and .cue extensions.
+      --input-format string          The format of the input, one of [auto, yaml, json, ndjson, jsonnet, cue]. When auto, it's detected from the file extension, the HTTP Content-Type header and the content. (default "auto")
+      --jpath stringArray            The directory(ies) searched for files imported by Jsonnet sources.
+      --lock-file string             The path to the lock file, created with 'oslo lock update'. (default "oslo.lock")
+      --locked                       Fail if a URL or git source is not recorded in the lock file or its checksum differs.
+      --max-archive-size int         The maximum total size in bytes of all files extracted from a single archive. (default 268435456)
+      --max-file-size int            The maximum size in bytes of a local file, standard input or a file in an archive, 0 means no limit.
+      --normalize                    Rewrite durations, numbers and times to their canonical representation and report every rewrite.
+      --offline                      Never access the network, serve URL and git sources only from the cache.
+  -o, --output string                The output format, one of [json, yaml]. (default "yaml")
+  -R, --recursive                    Process the directory used in -f, --filename recursively. Useful when you want to manage related manifests organized within the same directory.
+      --stream                       Read, decode and format one document at a time, to format large files with bounded memory.
+      --values stringArray           The YAML file(s) with values for sources written as Go templates, later files override earlier ones. Sources are rendered only if at least one values file is provided.
+
+Global Flags:
+      --config string   The path to the oslo config file. Defaults to the value of OSLO_CONFIG environment variable or oslo/config.yaml in the user config directory.
+`,
+			wantErr: false,
+		},
+		{
+			name: "split command exists",
+			args: []string{"split", "--help"},
+			wantOut: `Splits the provided input into files containing a single object each.
+
+The path of each file is built from the --template, which is a Go template executed
+with the object's .APIVersion, .Kind and .Name, and .Ext, the extension of the --output format,
+for example: {{.Kind}}/{{.Name}}.yaml.
+
+Usage:
+  oslo split [FILE...] [flags]
 
 Flags:
-  -f, --file stringArray   The file(s) that contain the configurations.
-  -h, --help               help for fmt
-  -o, --output string      The output format, one of [json, yaml]. (default "yaml")
-  -R, --recursive          Process the directory used in -f, --filename recursively. Useful when you want to manage related manifests organized within the same directory.
+      --allow-decrypted              Allow writing the decrypted content of SOPS encrypted sources in plaintext.
+      --allowed-host stringArray     The only host(s) URL and git sources can be fetched from, e.g. example.com or *.example.com.
+      --delete-sources               Delete the original files once all objects were written.
+      --denied-host stringArray      The host(s) URL and git sources must not be fetched from, takes precedence over --allowed-host.
+  -d, --dir string                   The directory in which split files are written. (default ".")
+      --exclude stringArray          Glob pattern(s) of files and directories to skip in directories.
+      --expand-env                   Replace ${VAR} and ${VAR:-default} references with environment variables before decoding, use $$ for a literal $.
+      --expand-env-strict            Fail if a variable referenced in the input is not set and has no default, implies --expand-env.
+      --ext-code stringArray         The external variable(s) of Jsonnet sources with Jsonnet code as their values, in 'name=code' format.
+      --ext-str stringArray          The external string variable(s) of Jsonnet sources, in 'name=value' format. The value is read from the environment variable of the same name if omitted.
+  -f, --file stringArray             The file(s) that contain the configurations. They can also be passed as arguments.
+      --follow-symlinks              Traverse symbolic links to directories when processing directories recursively.
+      --gitignore                    Skip files and directories listed in .gitignore files, in addition to .osloignore files.
+  -h, --help                         help for split
+      --hidden                       Read files and traverse directories whose names start with a dot.
+      --http-ca-file string          The PEM encoded CA bundle used to verify HTTPS servers, in addition to the system certificates.
+      --http-cache                   Cache downloaded files and revalidate them with the server using ETag and Last-Modified headers. (default true)
+      --http-cache-authenticated     Also cache files downloaded with --http-token or --http-header, separately for every set of them.
+      --http-cert-file string        The PEM encoded client certificate used for mutual TLS, requires --http-key-file.
+      --http-header stringArray      The header(s) added to HTTP requests, in 'Name: value' format.
+      --http-insecure-skip-verify    Skip verification of HTTPS server certificates. Insecure, use only for testing.
+      --http-key-file string         The PEM encoded private key of the client certificate.
+      --http-max-response-size int   The maximum size in bytes of a downloaded file, 0 means no limit.
+      --http-no-proxy string         Comma separated list of hosts which are not reached through --http-proxy.
+      --http-proxy string            The proxy URL used for HTTP requests. Defaults to HTTP_PROXY and HTTPS_PROXY environment variables.
+      --http-retries int             The number of times an HTTP request is retried after a network error or a 5xx response. (default 3)
+      --http-timeout duration        The timeout of a single HTTP request made to download a file, 0 means no timeout. (default 30s)
+      --http-token string            The bearer token sent with HTTP requests. Defaults to the value of OSLO_HTTP_TOKEN environment variable.
+      --include stringArray          Glob pattern(s) of files to read from directories. Defaults to files with .yaml, .yml, .json, .ndjson, .jsonl, .jsonnet and .cue extensions.
+      --input-format string          The format of the input, one of [auto, yaml, json, ndjson, jsonnet, cue]. When auto, it's detected from the file extension, the HTTP Content-Type header and the content. (default "auto")
+      --jpath stringArray            The directory(ies) searched for files imported by Jsonnet sources.
+      --lock-file string             The path to the lock file, created with 'oslo lock update'. (default "oslo.lock")
+      --locked                       Fail if a URL or git source is not recorded in the lock file or its checksum differs.
+      --max-archive-size int         The maximum total size in bytes of all files extracted from a single archive. (default 268435456)
+      --max-file-size int            The maximum size in bytes of a local file, standard input or a file in an archive, 0 means no limit.
+      --offline                      Never access the network, serve URL and git sources only from the cache.
+  -o, --output string                The output format, one of [json, yaml]. (default "yaml")
+      --overwrite                    Overwrite files which already exist.
+  -R, --recursive                    Process the directory used in -f, --filename recursively. Useful when you want to manage related manifests organized within the same directory.
+  -t, --template string              The template used to build each object's file path, relative to --dir. (default "{{.Kind}}/{{.Name}}{{.Ext}}")
+
+Global Flags:
+      --config string   The path to the oslo config file. Defaults to the value of OSLO_CONFIG environment variable or oslo/config.yaml in the user config directory.
 `,
 			wantErr: false,
 		},
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"github.com/OpenSLO/go-sdk/pkg/openslo"
+	"github.com/spf13/cobra"
+
+	"github.com/OpenSLO/oslo/internal/schema"
+)
+
+// NewSchemaCmd returns a new command for exporting OpenSLO schemas.
+func NewSchemaCmd() *cobra.Command {
+	schemaCmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Exports definitions of OpenSLO objects.",
+	}
+	schemaCmd.AddCommand(newSchemaCUECmd())
+	return schemaCmd
+}
+
+func newSchemaCUECmd() *cobra.Command {
+	var (
+		apiVersion string
+		pkg        string
+	)
+
+	cueCmd := &cobra.Command{
+		Use:   "cue",
+		Short: "Exports CUE definitions of OpenSLO objects.",
+		Long: `Exports CUE definitions of OpenSLO objects.
+
+Every object kind is exported as a definition, e.g. #SLO, and #Object is a disjunction of all of them.
+Save the output in your CUE module and unify your manifests with the definitions to type-check them:
+
+  oslo schema cue > openslo/openslo.cue`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			version, err := openslo.ParseVersion(apiVersion)
+			if err != nil {
+				return err
+			}
+			data, err := schema.CUE(version, pkg)
+			if err != nil {
+				return err
+			}
+			_, err = cmd.OutOrStdout().Write(data)
+			return err
+		},
+	}
+	cueCmd.Flags().StringVar(
+		&apiVersion, "api-version", openslo.VersionV1.String(),
+		"The OpenSLO version of exported objects.",
+	)
+	cueCmd.Flags().StringVar(
+		&pkg, "package", "openslo",
+		"The name of the CUE package the definitions are declared in.",
+	)
+	return cueCmd
+}
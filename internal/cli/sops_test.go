@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenSLO/oslo/internal/files"
+)
+
+func TestDecryptedOutput(t *testing.T) {
+	sopsDir := filepath.Join("..", "files", "testdata", "sops")
+	t.Setenv("SOPS_AGE_KEY_FILE", filepath.Join(sopsDir, "age.key"))
+
+	// copySource copies the encrypted fixture, so that it can be deleted by split.
+	copySource := func(t *testing.T) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(sopsDir, "datasource.yaml"))
+		require.NoError(t, err)
+		source := filepath.Join(t.TempDir(), "datasource.yaml")
+		require.NoError(t, os.WriteFile(source, data, 0o600))
+		return source
+	}
+	execute := func(args ...string) (string, error) {
+		out := new(bytes.Buffer)
+		root := NewRootCmd("testVersion")
+		root.SetOut(out)
+		root.SetErr(io.Discard)
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	t.Run("bundle refuses decrypted output", func(t *testing.T) {
+		out, err := execute("bundle", copySource(t))
+		require.ErrorIs(t, err, files.ErrDecryptedOutput)
+		assert.ErrorContains(t, err, "pass --allow-decrypted to write it anyway")
+		assert.Empty(t, out)
+	})
+
+	t.Run("bundle with --allow-decrypted", func(t *testing.T) {
+		out, err := execute("bundle", "--allow-decrypted", copySource(t))
+		require.NoError(t, err)
+		assert.Contains(t, out, "s3cr3t")
+	})
+
+	t.Run("split refuses decrypted output", func(t *testing.T) {
+		source := copySource(t)
+		dir := t.TempDir()
+		out, err := execute("split", "--dir", dir, "--delete-sources", source)
+		require.ErrorIs(t, err, files.ErrDecryptedOutput)
+		assert.Empty(t, out)
+		assert.FileExists(t, source)
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("split with --allow-decrypted", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := execute("split", "--dir", dir, "--allow-decrypted", copySource(t))
+		require.NoError(t, err)
+		data, err := os.ReadFile(filepath.Join(dir, "DataSource", "prometheus.yaml"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "s3cr3t")
+	})
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/OpenSLO/oslo/internal/files"
+)
+
+// NewSplitCmd returns a new command for splitting files into one object per file layout.
+func NewSplitCmd() *cobra.Command {
+	var (
+		fileFlags fileFlags
+		output    string
+		opts      files.SplitOptions
+	)
+
+	splitCmd := &cobra.Command{
+		Use:   "split [FILE...]",
+		Short: "Splits the provided input into files containing a single object each.",
+		Long: `Splits the provided input into files containing a single object each.
+
+The path of each file is built from the --template, which is a Go template executed
+with the object's .APIVersion, .Kind and .Name, and .Ext, the extension of the --output format,
+for example: {{.Kind}}/{{.Name}}.yaml.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			discoveredFilePaths, err := fileFlags.discoverFiles(cmd.Context(), args)
+			if err != nil {
+				return err
+			}
+			opts.Format, err = parseOutputFormat(output)
+			if err != nil {
+				return err
+			}
+			readOpts, err := fileFlags.readOptions()
+			if err != nil {
+				return err
+			}
+			objectsPerSource, err := files.ReadObjects(cmd.Context(), discoveredFilePaths, readOpts)
+			if err != nil {
+				return err
+			}
+			if err = fileFlags.checkDecryptedOutput(); err != nil {
+				return err
+			}
+			written, err := files.Split(objectsPerSource, opts)
+			for _, path := range written {
+				fmt.Fprintln(cmd.OutOrStdout(), path)
+			}
+			return err
+		},
+	}
+	registerFileRelatedFlags(splitCmd, &fileFlags)
+	registerAllowDecryptedFlag(splitCmd, &fileFlags)
+	splitCmd.Flags().StringVarP(
+		&output, "output", "o", "yaml",
+		"The output format, one of [json, yaml].",
+	)
+	splitCmd.Flags().StringVarP(
+		&opts.Dir, "dir", "d", ".",
+		"The directory in which split files are written.",
+	)
+	splitCmd.Flags().StringVarP(
+		&opts.PathTemplate, "template", "t", files.DefaultSplitPathTemplate,
+		"The template used to build each object's file path, relative to --dir.",
+	)
+	splitCmd.Flags().BoolVar(
+		&opts.Overwrite, "overwrite", false,
+		"Overwrite files which already exist.",
+	)
+	splitCmd.Flags().BoolVar(
+		&opts.DeleteSources, "delete-sources", false,
+		"Delete the original files once all objects were written.",
+	)
+	return splitCmd
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"maps"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/OpenSLO/go-sdk/pkg/openslo"
 	"github.com/OpenSLO/go-sdk/pkg/openslosdk"
 
 	"github.com/OpenSLO/oslo/internal/files"
@@ -17,40 +19,32 @@ import (
 
 // NewValidateCmd returns a new cobra.Command for the validate command.
 func NewValidateCmd() *cobra.Command {
-	var passedFilePaths []string
-	var recursive bool
+	var (
+		fileFlags fileFlags
+		stream    bool
+	)
 
 	validateCmd := &cobra.Command{
-		Use:   "validate",
+		Use:   "validate [FILE...]",
 		Short: "Validates your yaml file against the OpenSLO spec.",
 		Long:  `Validates your yaml file against the OpenSLO spec.`,
-		Args:  cobra.MinimumNArgs(0),
+		Args:  cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			discoveredFilePaths, err := files.Discover(passedFilePaths, recursive)
+			discoveredFilePaths, err := fileFlags.discoverFiles(cmd.Context(), args)
 			if err != nil {
 				return err
 			}
-			objectsPerSource, err := files.ReadObjects(discoveredFilePaths)
+			readOpts, err := fileFlags.readOptions()
 			if err != nil {
 				return err
 			}
-			sources := slices.Sorted(maps.Keys(objectsPerSource))
-
-			hasErrors := false
-			for _, src := range sources {
-				objects := objectsPerSource[src]
-				switch len(objects) {
-				case 1:
-					if err = objects[0].Validate(); err != nil {
-						hasErrors = true
-						printStderr(fmt.Errorf("Errors in %s:\n%s", src, indentString(err.Error(), 2)))
-					}
-				default:
-					if err = openslosdk.Validate(objects...); err != nil {
-						hasErrors = true
-						printStderr(fmt.Errorf("Errors in %s:\n%s", src, indentString(err.Error(), 2)))
-					}
-				}
+			validateFunc := validateSources
+			if stream {
+				validateFunc = validateSourcesStream
+			}
+			hasErrors, err := validateFunc(cmd.Context(), discoveredFilePaths, readOpts)
+			if err != nil {
+				return err
 			}
 			if !hasErrors {
 				printStderr("Valid!")
@@ -59,10 +53,80 @@ func NewValidateCmd() *cobra.Command {
 			return errors.New("Configuration is not valid!")
 		},
 	}
-	registerFileRelatedFlags(validateCmd, &passedFilePaths, &recursive)
+	registerFileRelatedFlags(validateCmd, &fileFlags)
+	registerValuesFlag(validateCmd, &fileFlags)
+	registerExtractKubernetesFlags(validateCmd, &fileFlags)
+	validateCmd.Flags().BoolVar(
+		&stream, "stream", false,
+		"Read, decode and validate one document at a time, to validate large files with bounded memory.",
+	)
 	return validateCmd
 }
 
+// validateSources reads all objects and validates them, it reports whether any of them is invalid.
+func validateSources(ctx context.Context, paths []string, readOpts files.ReadOptions) (bool, error) {
+	objectsPerSource, err := files.ReadObjects(ctx, paths, readOpts)
+	if err != nil {
+		return false, err
+	}
+	sources := slices.Sorted(maps.Keys(objectsPerSource))
+
+	hasErrors := false
+	for _, src := range sources {
+		objects := objectsPerSource[src]
+		switch {
+		case readOpts.ExtractKubernetes:
+			// Objects are validated one by one, so that each error points to the resource it comes from.
+			for _, object := range objects {
+				if err = object.Validate(); err != nil {
+					hasErrors = true
+					printValidationError(src, object, err)
+				}
+			}
+		case len(objects) == 1:
+			if err = objects[0].Validate(); err != nil {
+				hasErrors = true
+				printStderr(fmt.Errorf("Errors in %s:\n%s", src, indentString(err.Error(), 2)))
+			}
+		default:
+			if err = openslosdk.Validate(objects...); err != nil {
+				hasErrors = true
+				printStderr(fmt.Errorf("Errors in %s:\n%s", src, indentString(err.Error(), 2)))
+			}
+		}
+	}
+	return hasErrors, nil
+}
+
+// validateSourcesStream validates objects one document at a time, it reports whether any of them is invalid.
+func validateSourcesStream(ctx context.Context, paths []string, readOpts files.ReadOptions) (bool, error) {
+	hasErrors := false
+	for _, src := range slices.Sorted(slices.Values(paths)) {
+		err := files.StreamObjects(ctx, src, readOpts, func(index int, objects []openslo.Object) error {
+			for _, object := range objects {
+				if err := object.Validate(); err != nil {
+					hasErrors = true
+					printValidationError(fmt.Sprintf("%s (document %d)", src, index), object, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+	return hasErrors, nil
+}
+
+// printValidationError prints the validation error of a single object,
+// along with the Kubernetes resource the object was extracted from, if any.
+func printValidationError(location string, object openslo.Object, err error) {
+	if resource := files.KubernetesResource(object); resource != "" {
+		location = fmt.Sprintf("%s (%s)", location, resource)
+	}
+	printStderr(fmt.Errorf("Errors in %s:\n%s", location, indentString(err.Error(), 2)))
+}
+
 func indentString(s string, i int) string {
 	indent := strings.Repeat(" ", i)
 	split := strings.Split(s, "\n")
@@ -0,0 +1,112 @@
+// Package config loads the oslo configuration file.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Env is the environment variable which holds the path to the configuration file.
+const Env = "OSLO_CONFIG"
+
+// Config is the oslo configuration file.
+// Every value set in the file is used as the default of the matching command line flag.
+type Config struct {
+	HTTP HTTP `json:"http"`
+	// MaxFileSize is the maximum size in bytes of a local file, standard input or a file in an archive.
+	MaxFileSize *int64 `json:"maxFileSize,omitempty"`
+	// MaxArchiveSize is the maximum total size in bytes of all files extracted from a single archive.
+	MaxArchiveSize *int64 `json:"maxArchiveSize,omitempty"`
+}
+
+// HTTP configures how files are downloaded from URLs.
+type HTTP struct {
+	Timeout            *Duration         `json:"timeout,omitempty"`
+	Retries            *int              `json:"retries,omitempty"`
+	Headers            map[string]string `json:"headers,omitempty"`
+	Token              string            `json:"token,omitempty"`
+	CAFile             string            `json:"caFile,omitempty"`
+	CertFile           string            `json:"certFile,omitempty"`
+	KeyFile            string            `json:"keyFile,omitempty"`
+	Proxy              string            `json:"proxy,omitempty"`
+	NoProxy            string            `json:"noProxy,omitempty"`
+	InsecureSkipVerify *bool             `json:"insecureSkipVerify,omitempty"`
+	Cache              *bool             `json:"cache,omitempty"`
+	CacheAuthenticated *bool             `json:"cacheAuthenticated,omitempty"`
+	AllowedHosts       []string          `json:"allowedHosts,omitempty"`
+	DeniedHosts        []string          `json:"deniedHosts,omitempty"`
+	MaxResponseSize    *int64            `json:"maxResponseSize,omitempty"`
+}
+
+// Duration is a [time.Duration] encoded as a string, e.g. 1m30s.
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string: %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+// DefaultPath returns the path of the configuration file used when neither
+// the path is provided explicitly nor the [Env] variable is set.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "oslo", "config.yaml"), nil
+}
+
+// Load reads the configuration file from the provided path.
+// If the path is empty, it falls back to the [Env] variable and then to [DefaultPath].
+// A missing file is only an error if its path was provided explicitly.
+func Load(path string) (*Config, error) {
+	explicit := true
+	if path == "" {
+		path = os.Getenv(Env)
+	}
+	if path == "" {
+		explicit = false
+		var err error
+		if path, err = DefaultPath(); err != nil {
+			return &Config{}, nil
+		}
+	}
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var conf Config
+	if err = yaml.UnmarshalStrict(data, &conf); err != nil {
+		return nil, fmt.Errorf("failed to decode config file %s: %w", path, err)
+	}
+	conf.resolvePaths(filepath.Dir(path))
+	return &conf, nil
+}
+
+// resolvePaths makes relative paths in the configuration relative to the configuration file directory.
+func (c *Config) resolvePaths(dir string) {
+	for _, p := range []*string{&c.HTTP.CAFile, &c.HTTP.CertFile, &c.HTTP.KeyFile} {
+		if *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(dir, *p)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	writeConfig := func(t *testing.T, content string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	t.Run("explicit path", func(t *testing.T) {
+		path := writeConfig(t, `
+http:
+  timeout: 1m30s
+  retries: 5
+  headers:
+    X-Team: platform
+  caFile: certs/ca.pem
+  certFile: /etc/oslo/client.pem
+  keyFile: /etc/oslo/client-key.pem
+  proxy: http://proxy:3128
+  noProxy: localhost
+  insecureSkipVerify: true
+`)
+		conf, err := Load(path)
+		require.NoError(t, err)
+		retries, insecure := 5, true
+		assert.Equal(t, &Config{HTTP: HTTP{
+			Timeout:            &Duration{90 * time.Second},
+			Retries:            &retries,
+			Headers:            map[string]string{"X-Team": "platform"},
+			CAFile:             filepath.Join(filepath.Dir(path), "certs", "ca.pem"),
+			CertFile:           "/etc/oslo/client.pem",
+			KeyFile:            "/etc/oslo/client-key.pem",
+			Proxy:              "http://proxy:3128",
+			NoProxy:            "localhost",
+			InsecureSkipVerify: &insecure,
+		}}, conf)
+	})
+
+	t.Run("path from environment variable", func(t *testing.T) {
+		t.Setenv(Env, writeConfig(t, "http:\n  proxy: http://proxy:3128\n"))
+		conf, err := Load("")
+		require.NoError(t, err)
+		assert.Equal(t, "http://proxy:3128", conf.HTTP.Proxy)
+	})
+
+	t.Run("missing default config", func(t *testing.T) {
+		t.Setenv(Env, "")
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		t.Setenv("HOME", t.TempDir())
+		conf, err := Load("")
+		require.NoError(t, err)
+		assert.Equal(t, &Config{}, conf)
+	})
+
+	t.Run("missing explicit config", func(t *testing.T) {
+		_, err := Load(filepath.Join(t.TempDir(), "config.yaml"))
+		require.ErrorContains(t, err, "failed to read config file")
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := Load(writeConfig(t, "http:\n  proxyURL: http://proxy:3128\n"))
+		require.ErrorContains(t, err, `unknown field "proxyURL"`)
+	})
+
+	t.Run("invalid duration", func(t *testing.T) {
+		_, err := Load(writeConfig(t, "http:\n  timeout: 10 minutes\n"))
+		require.ErrorContains(t, err, `unknown unit " minutes"`)
+	})
+}
@@ -0,0 +1,134 @@
+// Package convert translates OpenSLO objects between the API versions supported by [openslosdk.Decode].
+//
+// Objects are converted one version at a time, openslo/v1 being the hub between
+// openslo/v1alpha and openslo.com/v2alpha. Every field which cannot be carried over to the target
+// version without losing information is reported as a [Loss].
+package convert
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/OpenSLO/go-sdk/pkg/openslo"
+	v1 "github.com/OpenSLO/go-sdk/pkg/openslo/v1"
+	v1alpha "github.com/OpenSLO/go-sdk/pkg/openslo/v1alpha"
+	v2alpha "github.com/OpenSLO/go-sdk/pkg/openslo/v2alpha"
+)
+
+// SupportedVersions lists the versions objects can be converted between, from the oldest to the newest.
+var SupportedVersions = []openslo.Version{
+	openslo.VersionV1alpha,
+	openslo.VersionV1,
+	openslo.VersionV2alpha,
+}
+
+// Loss describes a single field which could not be carried over to the target version as it was.
+type Loss struct {
+	// Path is the path to the field within the object as it was before the conversion step
+	// which lost it, e.g. metadata.labels.team.
+	Path string
+	// Message explains what happened to the field.
+	Message string
+}
+
+func (l Loss) String() string {
+	return fmt.Sprintf("%s: %s", l.Path, l.Message)
+}
+
+// Convert translates the object to the provided version.
+// Objects which already are in that version are returned as they are.
+// The returned object is not validated, fields with values unsupported by the target version
+// are kept and reported as a [Loss], leaving it up to validation to reject them.
+func Convert(object openslo.Object, version openslo.Version) (openslo.Object, []Loss, error) {
+	from := slices.Index(SupportedVersions, object.GetVersion())
+	if from == -1 {
+		return nil, nil, fmt.Errorf("unsupported source version: %s", object.GetVersion())
+	}
+	to := slices.Index(SupportedVersions, version)
+	if to == -1 {
+		return nil, nil, fmt.Errorf("unsupported target version: %s", version)
+	}
+	c := &converter{}
+	var err error
+	for from != to {
+		switch {
+		case from < to:
+			object, err = c.upgrade(object)
+			from++
+		default:
+			object, err = c.downgrade(object)
+			from--
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return object, c.losses, nil
+}
+
+// converter accumulates losses of a single conversion.
+type converter struct {
+	losses []Loss
+}
+
+func (c *converter) lose(path, format string, a ...any) {
+	c.losses = append(c.losses, Loss{Path: path, Message: fmt.Sprintf(format, a...)})
+}
+
+// upgrade converts the object to the next version.
+func (c *converter) upgrade(object openslo.Object) (openslo.Object, error) {
+	switch v := object.(type) {
+	case v1alpha.Service:
+		return c.v1alphaServiceToV1(v), nil
+	case v1alpha.SLO:
+		return c.v1alphaSLOToV1(v), nil
+	case v1.Service:
+		return c.v1ServiceToV2alpha(v), nil
+	case v1.SLO:
+		return c.v1SLOToV2alpha(v), nil
+	case v1.SLI:
+		return c.v1SLIToV2alpha(v), nil
+	case v1.DataSource:
+		return c.v1DataSourceToV2alpha(v), nil
+	case v1.AlertPolicy:
+		return c.v1AlertPolicyToV2alpha(v), nil
+	case v1.AlertCondition:
+		return c.v1AlertConditionToV2alpha(v), nil
+	case v1.AlertNotificationTarget:
+		return c.v1AlertNotificationTargetToV2alpha(v), nil
+	default:
+		return nil, unsupportedObjectError(object)
+	}
+}
+
+// downgrade converts the object to the previous version.
+func (c *converter) downgrade(object openslo.Object) (openslo.Object, error) {
+	switch v := object.(type) {
+	case v1.Service:
+		return c.v1ServiceToV1alpha(v), nil
+	case v1.SLO:
+		return c.v1SLOToV1alpha(v), nil
+	case v2alpha.Service:
+		return c.v2alphaServiceToV1(v), nil
+	case v2alpha.SLO:
+		return c.v2alphaSLOToV1(v), nil
+	case v2alpha.SLI:
+		return c.v2alphaSLIToV1(v), nil
+	case v2alpha.DataSource:
+		return c.v2alphaDataSourceToV1(v), nil
+	case v2alpha.AlertPolicy:
+		return c.v2alphaAlertPolicyToV1(v), nil
+	case v2alpha.AlertCondition:
+		return c.v2alphaAlertConditionToV1(v), nil
+	case v2alpha.AlertNotificationTarget:
+		return c.v2alphaAlertNotificationTargetToV1(v), nil
+	case v1.Object:
+		return nil, fmt.Errorf("%s: kind %s is not supported by %s", object, object.GetKind(), openslo.VersionV1alpha)
+	default:
+		return nil, unsupportedObjectError(object)
+	}
+}
+
+func unsupportedObjectError(object openslo.Object) error {
+	return fmt.Errorf("%s: unsupported object %T", object, object)
+}
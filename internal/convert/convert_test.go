@@ -0,0 +1,596 @@
+package convert_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenSLO/go-sdk/pkg/openslo"
+	"github.com/OpenSLO/go-sdk/pkg/openslosdk"
+	"sigs.k8s.io/yaml"
+
+	"github.com/OpenSLO/oslo/internal/convert"
+)
+
+func TestConvert(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		input      string
+		version    openslo.Version
+		wantOut    string
+		wantLosses []convert.Loss
+		wantErr    string
+	}{
+		{
+			name: "v1alpha threshold SLO to v1",
+			input: `apiVersion: openslo/v1alpha
+kind: SLO
+metadata:
+  name: web-latency
+  displayName: Web latency
+spec:
+  service: web
+  indicator:
+    thresholdMetric:
+      source: Prometheus
+      queryType: promql
+      query: latency_ms
+  timeWindows:
+    - unit: Second
+      count: 5400
+      isRolling: true
+  budgetingMethod: Occurrences
+  objectives:
+    - displayName: Good
+      value: 200
+      op: lt
+      target: 0.98
+`,
+			version: openslo.VersionV1,
+			wantOut: `apiVersion: openslo/v1
+kind: SLO
+metadata:
+  displayName: Web latency
+  name: web-latency
+spec:
+  budgetingMethod: Occurrences
+  indicator:
+    metadata:
+      name: web-latency
+    spec:
+      thresholdMetric:
+        metricSource:
+          spec:
+            query: latency_ms
+            queryType: promql
+          type: Prometheus
+  objectives:
+  - displayName: Good
+    op: lt
+    target: 0.98
+    value: 200
+  service: web
+  timeWindow:
+  - duration: 90m
+    isRolling: true
+`,
+		},
+		{
+			name: "v1alpha ratio metrics of each objective to v1",
+			input: `apiVersion: openslo/v1alpha
+kind: SLO
+metadata:
+  name: web-availability
+spec:
+  service: web
+  timeWindows:
+    - unit: Second
+      count: 90
+      isRolling: true
+  budgetingMethod: Occurrences
+  objectives:
+    - value: 1
+      target: 0.99
+      ratioMetrics:
+        good: {source: datadog, queryType: query, query: "sum:good"}
+        total: {source: datadog, queryType: query, query: "sum:total"}
+    - value: 1
+      target: 0.9
+      ratioMetrics:
+        incremental: true
+        good: {source: datadog, queryType: query, query: "sum:good"}
+        total: {source: datadog, queryType: query, query: "sum:all"}
+`,
+			version: openslo.VersionV1,
+			wantOut: `apiVersion: openslo/v1
+kind: SLO
+metadata:
+  name: web-availability
+spec:
+  budgetingMethod: Occurrences
+  objectives:
+  - indicator:
+      metadata:
+        name: web-availability-0
+      spec:
+        ratioMetric:
+          counter: false
+          good:
+            metricSource:
+              spec:
+                query: sum:good
+                queryType: query
+              type: datadog
+          total:
+            metricSource:
+              spec:
+                query: sum:total
+                queryType: query
+              type: datadog
+    target: 0.99
+  - indicator:
+      metadata:
+        name: web-availability-1
+      spec:
+        ratioMetric:
+          counter: true
+          good:
+            metricSource:
+              spec:
+                query: sum:good
+                queryType: query
+              type: datadog
+          total:
+            metricSource:
+              spec:
+                query: sum:all
+                queryType: query
+              type: datadog
+    target: 0.9
+  service: web
+  timeWindow:
+  - duration: 2m
+    isRolling: true
+`,
+			wantLosses: []convert.Loss{
+				{
+					Path:    "spec.timeWindows[0].count",
+					Message: "90 seconds were rounded up to 2 minutes, openslo/v1 has no unit smaller than a minute",
+				},
+				{Path: "spec.objectives[0].value", Message: "dropped, openslo/v1 forbids a value for ratio metrics"},
+				{Path: "spec.objectives[1].value", Message: "dropped, openslo/v1 forbids a value for ratio metrics"},
+			},
+		},
+		{
+			name: "v1 SLO to v1alpha",
+			input: `apiVersion: openslo/v1
+kind: SLO
+metadata:
+  name: web-availability
+  labels:
+    team: [a, b]
+spec:
+  service: web
+  indicator:
+    metadata:
+      name: web-availability-sli
+    spec:
+      ratioMetric:
+        counter: true
+        good:
+          metricSource:
+            type: Prometheus
+            spec:
+              query: good_total
+        total:
+          metricSource:
+            metricSourceRef: prometheus
+            spec:
+              query: requests_total
+              step: 60
+  timeWindow:
+    - duration: 1Y
+      isRolling: true
+  budgetingMethod: Occurrences
+  objectives:
+    - targetPercent: 99.5
+      timeSliceWindow: 5m
+  alertPolicies:
+    - alertPolicyRef: page
+`,
+			version: openslo.VersionV1alpha,
+			wantOut: `apiVersion: openslo/v1alpha
+kind: SLO
+metadata:
+  name: web-availability
+spec:
+  budgetingMethod: Occurrences
+  indicator: null
+  objectives:
+  - displayName: ""
+    ratioMetrics:
+      good:
+        query: good_total
+        queryType: ""
+        source: Prometheus
+      incremental: true
+      total:
+        query: requests_total
+        queryType: ""
+        source: ""
+    target: 0.995
+    value: 1
+  service: web
+  timeWindows:
+  - count: 4
+    isRolling: true
+    unit: Quarter
+`,
+			wantLosses: []convert.Loss{
+				{Path: "spec.indicator.metadata.name", Message: "dropped, openslo/v1alpha indicators have no name"},
+				{
+					Path:    "spec.indicator.spec.ratioMetric.total.metricSource.metricSourceRef",
+					Message: "dropped, openslo/v1alpha cannot reference a DataSource",
+				},
+				{
+					Path:    "spec.indicator.spec.ratioMetric.total.metricSource.spec.step",
+					Message: "dropped, openslo/v1alpha metric sources only have a query and a queryType",
+				},
+				{Path: "spec.objectives[0].timeSliceWindow", Message: "dropped, openslo/v1alpha has no time slice window"},
+				{Path: "spec.alertPolicies[0]", Message: "dropped, openslo/v1alpha has no alert policies"},
+				{Path: "metadata.labels.team", Message: "dropped, openslo/v1alpha has no labels"},
+			},
+		},
+		{
+			name: "v1 SLO to v2alpha",
+			input: `apiVersion: openslo/v1
+kind: SLO
+metadata:
+  name: web-availability
+  displayName: Web availability
+  labels:
+    team: [a, b]
+    env: prod
+  annotations:
+    owner: web
+spec:
+  service: web
+  indicatorRef: web-availability
+  timeWindow:
+    - duration: 1M
+      isRolling: false
+      calendar:
+        startTime: "2024-01-01 00:00:00"
+        timeZone: UTC
+  budgetingMethod: Timeslices
+  objectives:
+    - target: 0.99
+      timeSliceTarget: 0.9
+      timeSliceWindow: 1m
+`,
+			version: openslo.VersionV2alpha,
+			wantOut: `apiVersion: openslo.com/v2alpha
+kind: SLO
+metadata:
+  annotations:
+    owner: web
+  labels:
+    env: prod
+    team: a
+  name: web-availability
+spec:
+  budgetingMethod: Timeslices
+  objectives:
+  - target: 0.99
+    timeSliceTarget: 0.9
+    timeSliceWindow: 1m
+  service: web
+  sliRef: web-availability
+  timeWindow:
+  - calendar:
+      startTime: "2024-01-01 00:00:00"
+      timeZone: UTC
+    duration: 1M
+    isRolling: false
+`,
+			wantLosses: []convert.Loss{
+				{Path: "spec.timeWindow[0].duration", Message: `unit "M" is not supported by openslo.com/v2alpha`},
+				{Path: "metadata.displayName", Message: "dropped, openslo.com/v2alpha has no display names"},
+				{
+					Path:    "metadata.labels.team",
+					Message: "kept only the first of 2 values, openslo.com/v2alpha labels have a single value",
+				},
+			},
+		},
+		{
+			name: "v1 metric source to v2alpha",
+			input: `apiVersion: openslo/v1
+kind: SLI
+metadata:
+  name: latency
+spec:
+  thresholdMetric:
+    metricSource:
+      metricSourceRef: prometheus
+      type: Prometheus
+      spec:
+        query: latency_ms
+`,
+			version: openslo.VersionV2alpha,
+			wantOut: `apiVersion: openslo.com/v2alpha
+kind: SLI
+metadata:
+  name: latency
+spec:
+  thresholdMetric:
+    dataSourceRef: prometheus
+    spec:
+      query: latency_ms
+`,
+			wantLosses: []convert.Loss{
+				{
+					Path:    "spec.thresholdMetric.metricSource.type",
+					Message: "dropped, openslo.com/v2alpha uses the type of the referenced DataSource",
+				},
+			},
+		},
+		{
+			name: "v2alpha inline data source to v1",
+			input: `apiVersion: openslo.com/v2alpha
+kind: SLI
+metadata:
+  name: latency
+  labels:
+    team: web
+spec:
+  thresholdMetric:
+    dataSourceSpec:
+      type: Prometheus
+      connectionDetails:
+        url: http://prometheus:9090
+    spec:
+      query: latency_ms
+`,
+			version: openslo.VersionV1,
+			wantOut: `apiVersion: openslo/v1
+kind: SLI
+metadata:
+  labels:
+    team:
+    - web
+  name: latency
+spec:
+  thresholdMetric:
+    metricSource:
+      spec:
+        query: latency_ms
+      type: Prometheus
+`,
+			wantLosses: []convert.Loss{
+				{
+					Path:    "spec.thresholdMetric.dataSourceSpec.connectionDetails",
+					Message: "dropped, openslo/v1 metric sources have no connection details",
+				},
+			},
+		},
+		{
+			name: "v2alpha alert condition to v1",
+			input: `apiVersion: openslo.com/v2alpha
+kind: AlertCondition
+metadata:
+  name: fast-burn
+spec:
+  severity: page
+  condition:
+    kind: burnrate
+    op: gte
+    threshold: 14.4
+    lookbackWindow: 1h
+    alertAfter: 5m
+`,
+			version: openslo.VersionV1,
+			wantOut: `apiVersion: openslo/v1
+kind: AlertCondition
+metadata:
+  name: fast-burn
+spec:
+  condition:
+    alertAfter: 5m
+    kind: burnrate
+    lookbackWindow: 1h
+    op: gte
+    threshold: 14.4
+  severity: page
+`,
+		},
+		{
+			name: "same version",
+			input: `apiVersion: openslo/v1
+kind: Service
+metadata:
+  name: web
+  displayName: Web
+spec:
+  description: Web service
+`,
+			version: openslo.VersionV1,
+			wantOut: `apiVersion: openslo/v1
+kind: Service
+metadata:
+  displayName: Web
+  name: web
+spec:
+  description: Web service
+`,
+		},
+		{
+			name: "kind not supported by v1alpha",
+			input: `apiVersion: openslo.com/v2alpha
+kind: DataSource
+metadata:
+  name: prometheus
+spec:
+  type: Prometheus
+  connectionDetails:
+    url: http://prometheus:9090
+`,
+			version: openslo.VersionV1alpha,
+			wantErr: "v1.DataSource 'prometheus': kind DataSource is not supported by openslo/v1alpha",
+		},
+		{
+			name: "unsupported target version",
+			input: `apiVersion: openslo/v1
+kind: Service
+metadata:
+  name: web
+spec: {}
+`,
+			version: "openslo/v2",
+			wantErr: "unsupported target version: openslo/v2",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			objects, err := openslosdk.Decode(strings.NewReader(tc.input), openslosdk.FormatYAML)
+			require.NoError(t, err)
+			require.Len(t, objects, 1)
+
+			converted, losses, err := convert.Convert(objects[0], tc.version)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.version, converted.GetVersion())
+			assert.Equal(t, tc.wantLosses, losses)
+			out, err := yaml.Marshal(converted)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantOut, string(out))
+		})
+	}
+}
+
+func TestConvert_RoundTrip(t *testing.T) {
+	t.Parallel()
+	input := `- apiVersion: openslo/v1alpha
+  kind: Service
+  metadata:
+    displayName: Web
+    name: web
+  spec:
+    description: Web service
+- apiVersion: openslo/v1alpha
+  kind: SLO
+  metadata:
+    displayName: Web latency
+    name: web-latency
+  spec:
+    budgetingMethod: Occurrences
+    description: 98% of requests are served within 200ms
+    indicator:
+      thresholdMetric:
+        query: latency_ms
+        queryType: promql
+        source: Prometheus
+    objectives:
+    - displayName: Good
+      op: lt
+      ratioMetrics: null
+      target: 0.98
+      value: 200
+    service: web
+    timeWindows:
+    - calendar:
+        startTime: "2022-01-01 12:00:00"
+        timeZone: America/New_York
+      count: 1
+      isRolling: false
+      unit: Week
+- apiVersion: openslo/v1alpha
+  kind: SLO
+  metadata:
+    name: web-availability
+  spec:
+    budgetingMethod: Timeslices
+    indicator: null
+    objectives:
+    - displayName: Good
+      ratioMetrics:
+        good:
+          query: sum:requests{status:2xx}
+          queryType: query
+          source: datadog
+        incremental: true
+        total:
+          query: sum:requests
+          queryType: query
+          source: datadog
+      target: 0.995
+      timeSliceTarget: 0.95
+      value: 1
+    service: web
+    timeWindows:
+    - count: 3600
+      isRolling: true
+      unit: Second
+- apiVersion: openslo/v1alpha
+  kind: SLO
+  metadata:
+    name: api-availability
+  spec:
+    budgetingMethod: Occurrences
+    indicator: null
+    objectives:
+    - displayName: Reads
+      ratioMetrics:
+        good:
+          query: sum:requests{method:get,status:2xx}
+          queryType: query
+          source: datadog
+        incremental: false
+        total:
+          query: sum:requests{method:get}
+          queryType: query
+          source: datadog
+      target: 0.999
+      value: 1
+    - displayName: Writes
+      ratioMetrics:
+        good:
+          query: sum:requests{method:post,status:2xx}
+          queryType: query
+          source: datadog
+        incremental: false
+        total:
+          query: sum:requests{method:post}
+          queryType: query
+          source: datadog
+      target: 0.99
+      value: 1
+    service: api
+    timeWindows:
+    - count: 1
+      isRolling: true
+      unit: Month
+`
+	objects, err := openslosdk.Decode(strings.NewReader(input), openslosdk.FormatYAML)
+	require.NoError(t, err)
+
+	roundTripped := make([]openslo.Object, 0, len(objects))
+	for _, object := range objects {
+		converted, _, err := convert.Convert(object, openslo.VersionV1)
+		require.NoError(t, err)
+		converted, losses, err := convert.Convert(converted, openslo.VersionV1alpha)
+		require.NoError(t, err)
+		assert.Empty(t, losses)
+		roundTripped = append(roundTripped, converted)
+	}
+	buf := new(bytes.Buffer)
+	require.NoError(t, openslosdk.Encode(buf, openslosdk.FormatYAML, roundTripped...))
+	assert.Equal(t, input, buf.String())
+}
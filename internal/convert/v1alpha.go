@@ -0,0 +1,350 @@
+package convert
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	v1 "github.com/OpenSLO/go-sdk/pkg/openslo/v1"
+	v1alpha "github.com/OpenSLO/go-sdk/pkg/openslo/v1alpha"
+)
+
+// v1alphaRatioValue is the value conventionally set for objectives with ratio metrics,
+// openslo/v1alpha requires a value even though it is meaningless for them.
+const v1alphaRatioValue = 1.0
+
+// Keys of the openslo/v1 metric source spec which hold the openslo/v1alpha query.
+const (
+	metricSpecQueryKey     = "query"
+	metricSpecQueryTypeKey = "queryType"
+)
+
+func (c *converter) v1alphaServiceToV1(s v1alpha.Service) v1.Service {
+	return v1.NewService(
+		v1alphaMetadataToV1(s.Metadata),
+		v1.ServiceSpec{Description: s.Spec.Description},
+	)
+}
+
+func (c *converter) v1alphaSLOToV1(s v1alpha.SLO) v1.SLO {
+	spec := v1.SLOSpec{
+		Description:     s.Spec.Description,
+		Service:         s.Spec.Service,
+		BudgetingMethod: v1.SLOBudgetingMethod(s.Spec.BudgetingMethod),
+	}
+	for i, window := range s.Spec.TimeWindows {
+		path := fmt.Sprintf("spec.timeWindows[%d]", i)
+		spec.TimeWindow = append(spec.TimeWindow, c.v1alphaTimeWindowToV1(path, window))
+	}
+	if s.Spec.Indicator != nil {
+		spec.Indicator = &v1.SLOIndicatorInline{
+			Metadata: v1.Metadata{Name: s.Metadata.Name},
+			Spec:     v1.SLISpec{ThresholdMetric: v1alphaMetricSourceToV1(s.Spec.Indicator.ThresholdMetric)},
+		}
+	}
+	// Ratio metrics shared by all objectives become the indicator of the SLO,
+	// otherwise every objective gets its own indicator.
+	ratioMetrics := sharedRatioMetrics(s.Spec.Objectives)
+	if spec.Indicator == nil && ratioMetrics != nil {
+		spec.Indicator = &v1.SLOIndicatorInline{
+			Metadata: v1.Metadata{Name: s.Metadata.Name},
+			Spec:     v1.SLISpec{RatioMetric: v1alphaRatioMetricsToV1(*ratioMetrics)},
+		}
+	}
+	for i, objective := range s.Spec.Objectives {
+		path := fmt.Sprintf("spec.objectives[%d]", i)
+		converted := v1.SLOObjective{
+			DisplayName:     objective.DisplayName,
+			Operator:        v1.Operator(objective.Operator),
+			Value:           objective.Value,
+			Target:          objective.BudgetTarget,
+			TimeSliceTarget: objective.TimeSliceTarget,
+		}
+		if objective.RatioMetrics != nil {
+			if converted.Value != nil {
+				c.lose(path+".value", "dropped, %s forbids a value for ratio metrics", v1.APIVersion)
+				converted.Value = nil
+			}
+			if spec.Indicator == nil || spec.Indicator.Spec.RatioMetric == nil {
+				converted.Indicator = &v1.SLOIndicatorInline{
+					Metadata: v1.Metadata{Name: fmt.Sprintf("%s-%d", s.Metadata.Name, i)},
+					Spec:     v1.SLISpec{RatioMetric: v1alphaRatioMetricsToV1(*objective.RatioMetrics)},
+				}
+			}
+		}
+		spec.Objectives = append(spec.Objectives, converted)
+	}
+	return v1.NewSLO(v1alphaMetadataToV1(s.Metadata), spec)
+}
+
+func (c *converter) v1alphaTimeWindowToV1(path string, window v1alpha.SLOTimeWindow) v1.SLOTimeWindow {
+	converted := v1.SLOTimeWindow{IsRolling: window.IsRolling}
+	if window.Calendar != nil {
+		converted.Calendar = &v1.SLOCalendar{StartTime: window.Calendar.StartTime, TimeZone: window.Calendar.TimeZone}
+	}
+	switch window.Unit {
+	case v1alpha.SLOTimeWindowUnitSecond:
+		converted.Duration = c.secondsToV1(path, window.Count)
+	case v1alpha.SLOTimeWindowUnitDay:
+		converted.Duration = v1.NewDurationShorthand(window.Count, v1.DurationShorthandUnitDay)
+	case v1alpha.SLOTimeWindowUnitWeek:
+		converted.Duration = v1.NewDurationShorthand(window.Count, v1.DurationShorthandUnitWeek)
+	case v1alpha.SLOTimeWindowUnitMonth:
+		converted.Duration = v1.NewDurationShorthand(window.Count, v1.DurationShorthandUnitMonth)
+	case v1alpha.SLOTimeWindowUnitQuarter:
+		converted.Duration = v1.NewDurationShorthand(window.Count, v1.DurationShorthandUnitQuarter)
+	default:
+		c.lose(path+".unit", "unit %q is not supported by %s", window.Unit, v1.APIVersion)
+		converted.Duration = v1.NewDurationShorthand(window.Count, v1.DurationShorthandUnit(window.Unit))
+	}
+	return converted
+}
+
+// secondsToV1 converts a number of seconds to the largest unit which represents it exactly.
+// Since openslo/v1 has no unit smaller than a minute, the remaining seconds are rounded up.
+func (c *converter) secondsToV1(path string, seconds int) v1.DurationShorthand {
+	if seconds%60 != 0 {
+		minutes := seconds/60 + 1
+		c.lose(path+".count", "%d seconds were rounded up to %d minutes, %s has no unit smaller than a minute",
+			seconds, minutes, v1.APIVersion)
+		return v1.NewDurationShorthand(minutes, v1.DurationShorthandUnitMinute)
+	}
+	minutes := seconds / 60
+	switch {
+	case minutes%(60*24) == 0:
+		return v1.NewDurationShorthand(minutes/(60*24), v1.DurationShorthandUnitDay)
+	case minutes%60 == 0:
+		return v1.NewDurationShorthand(minutes/60, v1.DurationShorthandUnitHour)
+	default:
+		return v1.NewDurationShorthand(minutes, v1.DurationShorthandUnitMinute)
+	}
+}
+
+// sharedRatioMetrics returns the ratio metrics if all objectives have the same ones.
+func sharedRatioMetrics(objectives []v1alpha.SLOObjective) *v1alpha.SLORatioMetrics {
+	if len(objectives) == 0 || objectives[0].RatioMetrics == nil {
+		return nil
+	}
+	for _, objective := range objectives[1:] {
+		if !reflect.DeepEqual(objective.RatioMetrics, objectives[0].RatioMetrics) {
+			return nil
+		}
+	}
+	return objectives[0].RatioMetrics
+}
+
+func v1alphaRatioMetricsToV1(m v1alpha.SLORatioMetrics) *v1.SLIRatioMetric {
+	return &v1.SLIRatioMetric{
+		Counter: m.Incremental,
+		Good:    v1alphaMetricSourceToV1(m.Good),
+		Total:   v1alphaMetricSourceToV1(m.Total),
+	}
+}
+
+// v1alphaMetricSourceToV1 converts the metric source, its source becomes the metric source type
+// while the query and its type are carried over in the metric source spec.
+func v1alphaMetricSourceToV1(m v1alpha.SLOMetricSourceSpec) *v1.SLIMetricSpec {
+	spec := make(map[string]any)
+	if m.QueryType != "" {
+		spec[metricSpecQueryTypeKey] = m.QueryType
+	}
+	if m.Query != "" {
+		spec[metricSpecQueryKey] = m.Query
+	}
+	return &v1.SLIMetricSpec{MetricSource: v1.SLIMetricSource{Type: m.Source, Spec: spec}}
+}
+
+func v1alphaMetadataToV1(m v1alpha.Metadata) v1.Metadata {
+	return v1.Metadata{Name: m.Name, DisplayName: m.DisplayName}
+}
+
+func (c *converter) v1ServiceToV1alpha(s v1.Service) v1alpha.Service {
+	return v1alpha.NewService(
+		c.v1MetadataToV1alpha("metadata", s.Metadata),
+		v1alpha.ServiceSpec{Description: s.Spec.Description},
+	)
+}
+
+func (c *converter) v1SLOToV1alpha(s v1.SLO) v1alpha.SLO {
+	spec := v1alpha.SLOSpec{
+		Description:     s.Spec.Description,
+		Service:         s.Spec.Service,
+		BudgetingMethod: v1alpha.SLOBudgetingMethod(s.Spec.BudgetingMethod),
+	}
+	if s.Spec.BudgetingMethod == v1.SLOBudgetingMethodRatioTimeslices {
+		c.lose("spec.budgetingMethod", "%s budgeting method is not supported by %s",
+			s.Spec.BudgetingMethod, v1alpha.APIVersion)
+	}
+	for i, window := range s.Spec.TimeWindow {
+		path := fmt.Sprintf("spec.timeWindow[%d]", i)
+		spec.TimeWindows = append(spec.TimeWindows, c.v1TimeWindowToV1alpha(path, window))
+	}
+	if s.Spec.IndicatorRef != nil {
+		c.lose("spec.indicatorRef", "dropped, %s only supports inline indicators", v1alpha.APIVersion)
+	}
+	var ratioMetrics *v1alpha.SLORatioMetrics
+	if s.Spec.Indicator != nil {
+		c.v1IndicatorMetadataToV1alpha("spec.indicator", s.Metadata.Name, *s.Spec.Indicator)
+		sli := s.Spec.Indicator.Spec
+		if sli.ThresholdMetric != nil {
+			spec.Indicator = &v1alpha.SLOIndicator{
+				ThresholdMetric: c.v1MetricSpecToV1alpha("spec.indicator.spec.thresholdMetric", *sli.ThresholdMetric),
+			}
+		}
+		if sli.RatioMetric != nil {
+			ratioMetrics = c.v1RatioMetricToV1alpha("spec.indicator.spec.ratioMetric", *sli.RatioMetric)
+		}
+	}
+	for i, objective := range s.Spec.Objectives {
+		indicatorName := fmt.Sprintf("%s-%d", s.Metadata.Name, i)
+		converted := c.v1ObjectiveToV1alpha(fmt.Sprintf("spec.objectives[%d]", i), indicatorName, objective, ratioMetrics)
+		spec.Objectives = append(spec.Objectives, converted)
+	}
+	for i := range s.Spec.AlertPolicies {
+		c.lose(fmt.Sprintf("spec.alertPolicies[%d]", i), "dropped, %s has no alert policies", v1alpha.APIVersion)
+	}
+	return v1alpha.NewSLO(c.v1MetadataToV1alpha("metadata", s.Metadata), spec)
+}
+
+// v1ObjectiveToV1alpha converts the objective, setting the ratio metrics of the SLO indicator, if any.
+// The indicatorName is the name given to the objective's indicator when converting to openslo/v1.
+func (c *converter) v1ObjectiveToV1alpha(
+	path, indicatorName string,
+	objective v1.SLOObjective,
+	ratioMetrics *v1alpha.SLORatioMetrics,
+) v1alpha.SLOObjective {
+	converted := v1alpha.SLOObjective{
+		DisplayName:     objective.DisplayName,
+		Operator:        v1alpha.Operator(objective.Operator),
+		Value:           objective.Value,
+		BudgetTarget:    objective.Target,
+		TimeSliceTarget: objective.TimeSliceTarget,
+		RatioMetrics:    ratioMetrics,
+	}
+	if objective.TargetPercent != nil && objective.Target == nil {
+		target := *objective.TargetPercent / 100
+		converted.BudgetTarget = &target
+	}
+	switch {
+	case objective.Indicator != nil && objective.Indicator.Spec.RatioMetric != nil && ratioMetrics == nil:
+		// Objectives with their own ratio metrics are how openslo/v1alpha SLOs are converted to openslo/v1.
+		c.v1IndicatorMetadataToV1alpha(path+".indicator", indicatorName, *objective.Indicator)
+		converted.RatioMetrics = c.v1RatioMetricToV1alpha(
+			path+".indicator.spec.ratioMetric", *objective.Indicator.Spec.RatioMetric)
+	case objective.Indicator != nil:
+		c.lose(path+".indicator", "dropped, %s has no composite objectives", v1alpha.APIVersion)
+	}
+	if converted.RatioMetrics != nil && converted.Value == nil {
+		value := v1alphaRatioValue
+		converted.Value = &value
+	}
+	if objective.TimeSliceWindow != nil {
+		c.lose(path+".timeSliceWindow", "dropped, %s has no time slice window", v1alpha.APIVersion)
+	}
+	if objective.IndicatorRef != nil {
+		c.lose(path+".indicatorRef", "dropped, %s has no composite objectives", v1alpha.APIVersion)
+	}
+	if objective.CompositeWeight != nil {
+		c.lose(path+".compositeWeight", "dropped, %s has no composite objectives", v1alpha.APIVersion)
+	}
+	return converted
+}
+
+func (c *converter) v1TimeWindowToV1alpha(path string, window v1.SLOTimeWindow) v1alpha.SLOTimeWindow {
+	converted := v1alpha.SLOTimeWindow{IsRolling: window.IsRolling}
+	if window.Calendar != nil {
+		converted.Calendar = &v1alpha.SLOCalendar{StartTime: window.Calendar.StartTime, TimeZone: window.Calendar.TimeZone}
+	}
+	value := window.Duration.GetValue()
+	switch unit := window.Duration.GetUnit(); unit {
+	case v1.DurationShorthandUnitMinute:
+		converted.Unit, converted.Count = v1alpha.SLOTimeWindowUnitSecond, value*60
+	case v1.DurationShorthandUnitHour:
+		converted.Unit, converted.Count = v1alpha.SLOTimeWindowUnitSecond, value*60*60
+	case v1.DurationShorthandUnitDay:
+		converted.Unit, converted.Count = v1alpha.SLOTimeWindowUnitDay, value
+	case v1.DurationShorthandUnitWeek:
+		converted.Unit, converted.Count = v1alpha.SLOTimeWindowUnitWeek, value
+	case v1.DurationShorthandUnitMonth:
+		converted.Unit, converted.Count = v1alpha.SLOTimeWindowUnitMonth, value
+	case v1.DurationShorthandUnitQuarter:
+		converted.Unit, converted.Count = v1alpha.SLOTimeWindowUnitQuarter, value
+	case v1.DurationShorthandUnitYear:
+		converted.Unit, converted.Count = v1alpha.SLOTimeWindowUnitQuarter, value*4
+	default:
+		c.lose(path+".duration", "unit %q is not supported by %s", unit, v1alpha.APIVersion)
+		converted.Unit, converted.Count = v1alpha.SLOTimeWindowUnit(unit), value
+	}
+	return converted
+}
+
+// v1IndicatorMetadataToV1alpha reports the metadata and description of an inline indicator,
+// since openslo/v1alpha indicators have neither.
+// The name is not reported if it's the one given to indicators when converting to openslo/v1.
+func (c *converter) v1IndicatorMetadataToV1alpha(path, generatedName string, indicator v1.SLOIndicatorInline) {
+	c.v1MetadataToV1alpha(path+".metadata", indicator.Metadata)
+	if indicator.Metadata.Name != generatedName {
+		c.lose(path+".metadata.name", "dropped, %s indicators have no name", v1alpha.APIVersion)
+	}
+	if indicator.Metadata.DisplayName != "" {
+		c.lose(path+".metadata.displayName", "dropped, %s indicators have no display name", v1alpha.APIVersion)
+	}
+	if indicator.Spec.Description != "" {
+		c.lose(path+".spec.description", "dropped, %s indicators have no description", v1alpha.APIVersion)
+	}
+}
+
+func (c *converter) v1RatioMetricToV1alpha(path string, m v1.SLIRatioMetric) *v1alpha.SLORatioMetrics {
+	converted := &v1alpha.SLORatioMetrics{Incremental: m.Counter}
+	if m.Good != nil {
+		converted.Good = c.v1MetricSpecToV1alpha(path+".good", *m.Good)
+	}
+	if m.Total != nil {
+		converted.Total = c.v1MetricSpecToV1alpha(path+".total", *m.Total)
+	}
+	if m.Bad != nil {
+		c.lose(path+".bad", "dropped, %s ratio metrics only support good and total metrics", v1alpha.APIVersion)
+	}
+	if m.Raw != nil {
+		c.lose(path+".raw", "dropped, %s ratio metrics only support good and total metrics", v1alpha.APIVersion)
+	}
+	if m.RawType != "" {
+		c.lose(path+".rawType", "dropped, %s ratio metrics only support good and total metrics", v1alpha.APIVersion)
+	}
+	return converted
+}
+
+// v1MetricSpecToV1alpha converts the metric source, its type becomes the source
+// and the query and its type are read from the metric source spec.
+func (c *converter) v1MetricSpecToV1alpha(path string, m v1.SLIMetricSpec) v1alpha.SLOMetricSourceSpec {
+	path += ".metricSource"
+	source := m.MetricSource
+	converted := v1alpha.SLOMetricSourceSpec{Source: source.Type}
+	if source.MetricSourceRef != "" {
+		c.lose(path+".metricSourceRef", "dropped, %s cannot reference a DataSource", v1alpha.APIVersion)
+	}
+	for _, key := range slices.Sorted(maps.Keys(source.Spec)) {
+		value, isString := source.Spec[key].(string)
+		switch {
+		case key == metricSpecQueryKey && isString:
+			converted.Query = value
+		case key == metricSpecQueryTypeKey && isString:
+			converted.QueryType = value
+		default:
+			c.lose(path+".spec."+key, "dropped, %s metric sources only have a %s and a %s",
+				v1alpha.APIVersion, metricSpecQueryKey, metricSpecQueryTypeKey)
+		}
+	}
+	return converted
+}
+
+func (c *converter) v1MetadataToV1alpha(path string, m v1.Metadata) v1alpha.Metadata {
+	for _, key := range slices.Sorted(maps.Keys(m.Labels)) {
+		c.lose(path+".labels."+key, "dropped, %s has no labels", v1alpha.APIVersion)
+	}
+	for _, key := range slices.Sorted(maps.Keys(m.Annotations)) {
+		c.lose(path+".annotations."+key, "dropped, %s has no annotations", v1alpha.APIVersion)
+	}
+	return v1alpha.Metadata{Name: m.Name, DisplayName: m.DisplayName}
+}
@@ -0,0 +1,500 @@
+package convert
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	v1 "github.com/OpenSLO/go-sdk/pkg/openslo/v1"
+	v2alpha "github.com/OpenSLO/go-sdk/pkg/openslo/v2alpha"
+)
+
+func (c *converter) v1ServiceToV2alpha(s v1.Service) v2alpha.Service {
+	return v2alpha.NewService(
+		c.v1MetadataToV2alpha("metadata", s.Metadata),
+		v2alpha.ServiceSpec{Description: s.Spec.Description},
+	)
+}
+
+func (c *converter) v1SLOToV2alpha(s v1.SLO) v2alpha.SLO {
+	spec := v2alpha.SLOSpec{
+		Description:     s.Spec.Description,
+		Service:         s.Spec.Service,
+		SLI:             c.v1IndicatorToV2alpha("spec.indicator", s.Spec.Indicator),
+		SLIRef:          s.Spec.IndicatorRef,
+		BudgetingMethod: v2alpha.SLOBudgetingMethod(s.Spec.BudgetingMethod),
+	}
+	for i, window := range s.Spec.TimeWindow {
+		path := fmt.Sprintf("spec.timeWindow[%d]", i)
+		converted := v2alpha.SLOTimeWindow{
+			Duration:  c.v1DurationToV2alpha(path+".duration", window.Duration),
+			IsRolling: window.IsRolling,
+		}
+		if window.Calendar != nil {
+			converted.Calendar = &v2alpha.SLOCalendar{StartTime: window.Calendar.StartTime, TimeZone: window.Calendar.TimeZone}
+		}
+		spec.TimeWindow = append(spec.TimeWindow, converted)
+	}
+	for i, objective := range s.Spec.Objectives {
+		path := fmt.Sprintf("spec.objectives[%d]", i)
+		converted := v2alpha.SLOObjective{
+			DisplayName:     objective.DisplayName,
+			Operator:        v2alpha.Operator(objective.Operator),
+			Value:           objective.Value,
+			Target:          objective.Target,
+			TargetPercent:   objective.TargetPercent,
+			TimeSliceTarget: objective.TimeSliceTarget,
+			SLI:             c.v1IndicatorToV2alpha(path+".indicator", objective.Indicator),
+			SLIRef:          objective.IndicatorRef,
+			CompositeWeight: objective.CompositeWeight,
+		}
+		if objective.TimeSliceWindow != nil {
+			window := c.v1DurationToV2alpha(path+".timeSliceWindow", *objective.TimeSliceWindow)
+			converted.TimeSliceWindow = &window
+		}
+		spec.Objectives = append(spec.Objectives, converted)
+	}
+	for i, policy := range s.Spec.AlertPolicies {
+		path := fmt.Sprintf("spec.alertPolicies[%d]", i)
+		var converted v2alpha.SLOAlertPolicy
+		if policy.SLOAlertPolicyRef != nil {
+			converted.SLOAlertPolicyRef = &v2alpha.SLOAlertPolicyRef{AlertPolicyRef: policy.AlertPolicyRef}
+		}
+		if policy.SLOAlertPolicyInline != nil {
+			converted.SLOAlertPolicyInline = &v2alpha.SLOAlertPolicyInline{
+				Kind:     policy.SLOAlertPolicyInline.Kind,
+				Metadata: c.v1MetadataToV2alpha(path+".metadata", policy.SLOAlertPolicyInline.Metadata),
+				Spec:     c.v1AlertPolicySpecToV2alpha(path+".spec", policy.SLOAlertPolicyInline.Spec),
+			}
+		}
+		spec.AlertPolicies = append(spec.AlertPolicies, converted)
+	}
+	return v2alpha.NewSLO(c.v1MetadataToV2alpha("metadata", s.Metadata), spec)
+}
+
+func (c *converter) v1IndicatorToV2alpha(path string, indicator *v1.SLOIndicatorInline) *v2alpha.SLOSLIInline {
+	if indicator == nil {
+		return nil
+	}
+	return &v2alpha.SLOSLIInline{
+		Metadata: c.v1MetadataToV2alpha(path+".metadata", indicator.Metadata),
+		Spec:     c.v1SLISpecToV2alpha(path+".spec", indicator.Spec),
+	}
+}
+
+func (c *converter) v1SLIToV2alpha(s v1.SLI) v2alpha.SLI {
+	return v2alpha.NewSLI(
+		c.v1MetadataToV2alpha("metadata", s.Metadata),
+		c.v1SLISpecToV2alpha("spec", s.Spec),
+	)
+}
+
+func (c *converter) v1SLISpecToV2alpha(path string, s v1.SLISpec) v2alpha.SLISpec {
+	spec := v2alpha.SLISpec{
+		Description:     s.Description,
+		ThresholdMetric: c.v1MetricSpecToV2alpha(path+".thresholdMetric", s.ThresholdMetric),
+	}
+	if m := s.RatioMetric; m != nil {
+		path += ".ratioMetric"
+		spec.RatioMetric = &v2alpha.SLIRatioMetric{
+			Counter: m.Counter,
+			Good:    c.v1MetricSpecToV2alpha(path+".good", m.Good),
+			Bad:     c.v1MetricSpecToV2alpha(path+".bad", m.Bad),
+			Total:   c.v1MetricSpecToV2alpha(path+".total", m.Total),
+			RawType: v2alpha.SLIRawMetricType(m.RawType),
+			Raw:     c.v1MetricSpecToV2alpha(path+".raw", m.Raw),
+		}
+	}
+	return spec
+}
+
+// v1MetricSpecToV2alpha converts the metric source, a reference to a DataSource is carried over as it is,
+// while a metric source which only names its type becomes an inline DataSource spec of that type.
+func (c *converter) v1MetricSpecToV2alpha(path string, m *v1.SLIMetricSpec) *v2alpha.SLIMetricSpec {
+	if m == nil {
+		return nil
+	}
+	source := m.MetricSource
+	spec := &v2alpha.SLIMetricSpec{
+		DataSourceRef: source.MetricSourceRef,
+		Spec:          source.Spec,
+	}
+	switch {
+	case source.Type != "" && source.MetricSourceRef != "":
+		c.lose(path+".metricSource.type", "dropped, %s uses the type of the referenced DataSource", v2alpha.APIVersion)
+	case source.Type != "":
+		spec.DataSourceSpec = &v2alpha.DataSourceSpec{Type: source.Type}
+	}
+	return spec
+}
+
+func (c *converter) v1DataSourceToV2alpha(s v1.DataSource) v2alpha.DataSource {
+	return v2alpha.NewDataSource(
+		c.v1MetadataToV2alpha("metadata", s.Metadata),
+		v2alpha.DataSourceSpec{
+			Description:       s.Spec.Description,
+			Type:              s.Spec.Type,
+			ConnectionDetails: s.Spec.ConnectionDetails,
+		},
+	)
+}
+
+func (c *converter) v1AlertPolicyToV2alpha(s v1.AlertPolicy) v2alpha.AlertPolicy {
+	return v2alpha.NewAlertPolicy(
+		c.v1MetadataToV2alpha("metadata", s.Metadata),
+		c.v1AlertPolicySpecToV2alpha("spec", s.Spec),
+	)
+}
+
+func (c *converter) v1AlertPolicySpecToV2alpha(path string, s v1.AlertPolicySpec) v2alpha.AlertPolicySpec {
+	spec := v2alpha.AlertPolicySpec{
+		Description:        s.Description,
+		AlertWhenNoData:    s.AlertWhenNoData,
+		AlertWhenBreaching: s.AlertWhenBreaching,
+		AlertWhenResolved:  s.AlertWhenResolved,
+	}
+	for i, condition := range s.Conditions {
+		conditionPath := fmt.Sprintf("%s.conditions[%d]", path, i)
+		var converted v2alpha.AlertPolicyCondition
+		if condition.AlertPolicyConditionRef != nil {
+			converted.AlertPolicyConditionRef = &v2alpha.AlertPolicyConditionRef{ConditionRef: condition.ConditionRef}
+		}
+		if inline := condition.AlertPolicyConditionInline; inline != nil {
+			converted.AlertPolicyConditionInline = &v2alpha.AlertPolicyConditionInline{
+				Kind:     inline.Kind,
+				Metadata: c.v1MetadataToV2alpha(conditionPath+".metadata", inline.Metadata),
+				Spec:     c.v1AlertConditionSpecToV2alpha(conditionPath+".spec", inline.Spec),
+			}
+		}
+		spec.Conditions = append(spec.Conditions, converted)
+	}
+	for i, target := range s.NotificationTargets {
+		targetPath := fmt.Sprintf("%s.notificationTargets[%d]", path, i)
+		var converted v2alpha.AlertPolicyNotificationTarget
+		if target.AlertPolicyNotificationTargetRef != nil {
+			converted.AlertPolicyNotificationTargetRef = &v2alpha.AlertPolicyNotificationTargetRef{
+				TargetRef: target.TargetRef,
+			}
+		}
+		if inline := target.AlertPolicyNotificationTargetInline; inline != nil {
+			converted.AlertPolicyNotificationTargetInline = &v2alpha.AlertPolicyNotificationTargetInline{
+				Kind:     inline.Kind,
+				Metadata: c.v1MetadataToV2alpha(targetPath+".metadata", inline.Metadata),
+				Spec: v2alpha.AlertNotificationTargetSpec{
+					Description: inline.Spec.Description,
+					Target:      inline.Spec.Target,
+				},
+			}
+		}
+		spec.NotificationTargets = append(spec.NotificationTargets, converted)
+	}
+	return spec
+}
+
+func (c *converter) v1AlertConditionToV2alpha(s v1.AlertCondition) v2alpha.AlertCondition {
+	return v2alpha.NewAlertCondition(
+		c.v1MetadataToV2alpha("metadata", s.Metadata),
+		c.v1AlertConditionSpecToV2alpha("spec", s.Spec),
+	)
+}
+
+func (c *converter) v1AlertConditionSpecToV2alpha(path string, s v1.AlertConditionSpec) v2alpha.AlertConditionSpec {
+	path += ".condition"
+	spec := v2alpha.AlertConditionSpec{
+		Severity:    s.Severity,
+		Description: s.Description,
+		Condition: v2alpha.AlertConditionType{
+			Kind:           v2alpha.AlertConditionKind(s.Condition.Kind),
+			Operator:       v2alpha.Operator(s.Condition.Operator),
+			Threshold:      s.Condition.Threshold,
+			LookbackWindow: c.v1DurationToV2alpha(path+".lookbackWindow", s.Condition.LookbackWindow),
+		},
+	}
+	if s.Condition.AlertAfter != nil {
+		spec.Condition.AlertAfter = c.v1DurationToV2alpha(path+".alertAfter", *s.Condition.AlertAfter)
+	}
+	return spec
+}
+
+func (c *converter) v1AlertNotificationTargetToV2alpha(s v1.AlertNotificationTarget) v2alpha.AlertNotificationTarget {
+	return v2alpha.NewAlertNotificationTarget(
+		c.v1MetadataToV2alpha("metadata", s.Metadata),
+		v2alpha.AlertNotificationTargetSpec{
+			Description: s.Spec.Description,
+			Target:      s.Spec.Target,
+		},
+	)
+}
+
+// v1DurationToV2alpha converts the duration, calendar units have no exact equivalent in openslo.com/v2alpha,
+// so they are kept and reported.
+func (c *converter) v1DurationToV2alpha(path string, d v1.DurationShorthand) v2alpha.DurationShorthand {
+	unit := d.GetUnit()
+	switch unit {
+	case v1.DurationShorthandUnitMonth, v1.DurationShorthandUnitQuarter, v1.DurationShorthandUnitYear:
+		c.lose(path, "unit %q is not supported by %s", unit, v2alpha.APIVersion)
+	}
+	return v2alpha.NewDurationShorthand(d.GetValue(), v2alpha.DurationShorthandUnit(unit))
+}
+
+// v1MetadataToV2alpha converts the metadata, openslo.com/v2alpha labels have a single value,
+// so only the first value of each label is kept.
+func (c *converter) v1MetadataToV2alpha(path string, m v1.Metadata) v2alpha.Metadata {
+	metadata := v2alpha.Metadata{Name: m.Name}
+	if m.DisplayName != "" {
+		c.lose(path+".displayName", "dropped, %s has no display names", v2alpha.APIVersion)
+	}
+	for _, key := range slices.Sorted(maps.Keys(m.Labels)) {
+		if metadata.Labels == nil {
+			metadata.Labels = make(v2alpha.Labels, len(m.Labels))
+		}
+		values := m.Labels[key]
+		switch len(values) {
+		case 0:
+			metadata.Labels[key] = ""
+		case 1:
+			metadata.Labels[key] = values[0]
+		default:
+			metadata.Labels[key] = values[0]
+			c.lose(path+".labels."+key, "kept only the first of %d values, %s labels have a single value",
+				len(values), v2alpha.APIVersion)
+		}
+	}
+	if m.Annotations != nil {
+		metadata.Annotations = v2alpha.Annotations(maps.Clone(m.Annotations))
+	}
+	return metadata
+}
+
+func (c *converter) v2alphaServiceToV1(s v2alpha.Service) v1.Service {
+	return v1.NewService(
+		v2alphaMetadataToV1(s.Metadata),
+		v1.ServiceSpec{Description: s.Spec.Description},
+	)
+}
+
+func (c *converter) v2alphaSLOToV1(s v2alpha.SLO) v1.SLO {
+	spec := v1.SLOSpec{
+		Description:     s.Spec.Description,
+		Service:         s.Spec.Service,
+		Indicator:       c.v2alphaSLIToV1Indicator("spec.sli", s.Spec.SLI),
+		IndicatorRef:    s.Spec.SLIRef,
+		BudgetingMethod: v1.SLOBudgetingMethod(s.Spec.BudgetingMethod),
+	}
+	for _, window := range s.Spec.TimeWindow {
+		converted := v1.SLOTimeWindow{
+			Duration:  v2alphaDurationToV1(window.Duration),
+			IsRolling: window.IsRolling,
+		}
+		if window.Calendar != nil {
+			converted.Calendar = &v1.SLOCalendar{StartTime: window.Calendar.StartTime, TimeZone: window.Calendar.TimeZone}
+		}
+		spec.TimeWindow = append(spec.TimeWindow, converted)
+	}
+	for i, objective := range s.Spec.Objectives {
+		converted := v1.SLOObjective{
+			DisplayName:     objective.DisplayName,
+			Operator:        v1.Operator(objective.Operator),
+			Value:           objective.Value,
+			Target:          objective.Target,
+			TargetPercent:   objective.TargetPercent,
+			TimeSliceTarget: objective.TimeSliceTarget,
+			Indicator:       c.v2alphaSLIToV1Indicator(fmt.Sprintf("spec.objectives[%d].sli", i), objective.SLI),
+			IndicatorRef:    objective.SLIRef,
+			CompositeWeight: objective.CompositeWeight,
+		}
+		if objective.TimeSliceWindow != nil {
+			window := v2alphaDurationToV1(*objective.TimeSliceWindow)
+			converted.TimeSliceWindow = &window
+		}
+		spec.Objectives = append(spec.Objectives, converted)
+	}
+	for _, policy := range s.Spec.AlertPolicies {
+		var converted v1.SLOAlertPolicy
+		if policy.SLOAlertPolicyRef != nil {
+			converted.SLOAlertPolicyRef = &v1.SLOAlertPolicyRef{AlertPolicyRef: policy.AlertPolicyRef}
+		}
+		if policy.SLOAlertPolicyInline != nil {
+			converted.SLOAlertPolicyInline = &v1.SLOAlertPolicyInline{
+				Kind:     policy.SLOAlertPolicyInline.Kind,
+				Metadata: v2alphaMetadataToV1(policy.SLOAlertPolicyInline.Metadata),
+				Spec:     v2alphaAlertPolicySpecToV1(policy.SLOAlertPolicyInline.Spec),
+			}
+		}
+		spec.AlertPolicies = append(spec.AlertPolicies, converted)
+	}
+	return v1.NewSLO(v2alphaMetadataToV1(s.Metadata), spec)
+}
+
+func (c *converter) v2alphaSLIToV1Indicator(path string, sli *v2alpha.SLOSLIInline) *v1.SLOIndicatorInline {
+	if sli == nil {
+		return nil
+	}
+	return &v1.SLOIndicatorInline{
+		Metadata: v2alphaMetadataToV1(sli.Metadata),
+		Spec:     c.v2alphaSLISpecToV1(path+".spec", sli.Spec),
+	}
+}
+
+func (c *converter) v2alphaSLIToV1(s v2alpha.SLI) v1.SLI {
+	return v1.NewSLI(
+		v2alphaMetadataToV1(s.Metadata),
+		c.v2alphaSLISpecToV1("spec", s.Spec),
+	)
+}
+
+func (c *converter) v2alphaSLISpecToV1(path string, s v2alpha.SLISpec) v1.SLISpec {
+	spec := v1.SLISpec{
+		Description:     s.Description,
+		ThresholdMetric: c.v2alphaMetricSpecToV1(path+".thresholdMetric", s.ThresholdMetric),
+	}
+	if m := s.RatioMetric; m != nil {
+		path += ".ratioMetric"
+		spec.RatioMetric = &v1.SLIRatioMetric{
+			Counter: m.Counter,
+			Good:    c.v2alphaMetricSpecToV1(path+".good", m.Good),
+			Bad:     c.v2alphaMetricSpecToV1(path+".bad", m.Bad),
+			Total:   c.v2alphaMetricSpecToV1(path+".total", m.Total),
+			RawType: v1.SLIRawMetricType(m.RawType),
+			Raw:     c.v2alphaMetricSpecToV1(path+".raw", m.Raw),
+		}
+	}
+	return spec
+}
+
+// v2alphaMetricSpecToV1 converts the metric spec, an inline DataSource spec is reduced to its type,
+// since openslo/v1 metric sources cannot define connection details.
+func (c *converter) v2alphaMetricSpecToV1(path string, m *v2alpha.SLIMetricSpec) *v1.SLIMetricSpec {
+	if m == nil {
+		return nil
+	}
+	source := v1.SLIMetricSource{
+		MetricSourceRef: m.DataSourceRef,
+		Spec:            m.Spec,
+	}
+	if dataSource := m.DataSourceSpec; dataSource != nil {
+		path += ".dataSourceSpec"
+		source.Type = dataSource.Type
+		if dataSource.Description != "" {
+			c.lose(path+".description", "dropped, %s metric sources have no description", v1.APIVersion)
+		}
+		if len(dataSource.ConnectionDetails) > 0 {
+			c.lose(path+".connectionDetails", "dropped, %s metric sources have no connection details", v1.APIVersion)
+		}
+	}
+	return &v1.SLIMetricSpec{MetricSource: source}
+}
+
+func (c *converter) v2alphaDataSourceToV1(s v2alpha.DataSource) v1.DataSource {
+	return v1.NewDataSource(
+		v2alphaMetadataToV1(s.Metadata),
+		v1.DataSourceSpec{
+			Description:       s.Spec.Description,
+			Type:              s.Spec.Type,
+			ConnectionDetails: s.Spec.ConnectionDetails,
+		},
+	)
+}
+
+func (c *converter) v2alphaAlertPolicyToV1(s v2alpha.AlertPolicy) v1.AlertPolicy {
+	return v1.NewAlertPolicy(
+		v2alphaMetadataToV1(s.Metadata),
+		v2alphaAlertPolicySpecToV1(s.Spec),
+	)
+}
+
+func v2alphaAlertPolicySpecToV1(s v2alpha.AlertPolicySpec) v1.AlertPolicySpec {
+	spec := v1.AlertPolicySpec{
+		Description:        s.Description,
+		AlertWhenNoData:    s.AlertWhenNoData,
+		AlertWhenBreaching: s.AlertWhenBreaching,
+		AlertWhenResolved:  s.AlertWhenResolved,
+	}
+	for _, condition := range s.Conditions {
+		var converted v1.AlertPolicyCondition
+		if condition.AlertPolicyConditionRef != nil {
+			converted.AlertPolicyConditionRef = &v1.AlertPolicyConditionRef{ConditionRef: condition.ConditionRef}
+		}
+		if inline := condition.AlertPolicyConditionInline; inline != nil {
+			converted.AlertPolicyConditionInline = &v1.AlertPolicyConditionInline{
+				Kind:     inline.Kind,
+				Metadata: v2alphaMetadataToV1(inline.Metadata),
+				Spec:     v2alphaAlertConditionSpecToV1(inline.Spec),
+			}
+		}
+		spec.Conditions = append(spec.Conditions, converted)
+	}
+	for _, target := range s.NotificationTargets {
+		var converted v1.AlertPolicyNotificationTarget
+		if target.AlertPolicyNotificationTargetRef != nil {
+			converted.AlertPolicyNotificationTargetRef = &v1.AlertPolicyNotificationTargetRef{
+				TargetRef: target.TargetRef,
+			}
+		}
+		if inline := target.AlertPolicyNotificationTargetInline; inline != nil {
+			converted.AlertPolicyNotificationTargetInline = &v1.AlertPolicyNotificationTargetInline{
+				Kind:     inline.Kind,
+				Metadata: v2alphaMetadataToV1(inline.Metadata),
+				Spec: v1.AlertNotificationTargetSpec{
+					Description: inline.Spec.Description,
+					Target:      inline.Spec.Target,
+				},
+			}
+		}
+		spec.NotificationTargets = append(spec.NotificationTargets, converted)
+	}
+	return spec
+}
+
+func (c *converter) v2alphaAlertConditionToV1(s v2alpha.AlertCondition) v1.AlertCondition {
+	return v1.NewAlertCondition(
+		v2alphaMetadataToV1(s.Metadata),
+		v2alphaAlertConditionSpecToV1(s.Spec),
+	)
+}
+
+func v2alphaAlertConditionSpecToV1(s v2alpha.AlertConditionSpec) v1.AlertConditionSpec {
+	spec := v1.AlertConditionSpec{
+		Severity:    s.Severity,
+		Description: s.Description,
+		Condition: v1.AlertConditionType{
+			Kind:           v1.AlertConditionKind(s.Condition.Kind),
+			Operator:       v1.Operator(s.Condition.Operator),
+			Threshold:      s.Condition.Threshold,
+			LookbackWindow: v2alphaDurationToV1(s.Condition.LookbackWindow),
+		},
+	}
+	if s.Condition.AlertAfter.GetValue() != 0 {
+		alertAfter := v2alphaDurationToV1(s.Condition.AlertAfter)
+		spec.Condition.AlertAfter = &alertAfter
+	}
+	return spec
+}
+
+func (c *converter) v2alphaAlertNotificationTargetToV1(s v2alpha.AlertNotificationTarget) v1.AlertNotificationTarget {
+	return v1.NewAlertNotificationTarget(
+		v2alphaMetadataToV1(s.Metadata),
+		v1.AlertNotificationTargetSpec{
+			Description: s.Spec.Description,
+			Target:      s.Spec.Target,
+		},
+	)
+}
+
+func v2alphaDurationToV1(d v2alpha.DurationShorthand) v1.DurationShorthand {
+	return v1.NewDurationShorthand(d.GetValue(), v1.DurationShorthandUnit(d.GetUnit()))
+}
+
+func v2alphaMetadataToV1(m v2alpha.Metadata) v1.Metadata {
+	metadata := v1.Metadata{Name: m.Name}
+	for key, value := range m.Labels {
+		if metadata.Labels == nil {
+			metadata.Labels = make(v1.Labels, len(m.Labels))
+		}
+		metadata.Labels[key] = v1.Label{value}
+	}
+	if m.Annotations != nil {
+		metadata.Annotations = v1.Annotations(maps.Clone(m.Annotations))
+	}
+	return metadata
+}
@@ -0,0 +1,88 @@
+// Package envexpand substitutes environment variable references in manifests.
+package envexpand
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// LookupFunc returns the value of the variable and reports whether it's set, like [os.LookupEnv].
+type LookupFunc func(name string) (string, bool)
+
+// Options configures [Expand].
+type Options struct {
+	// Strict makes [Expand] fail if a referenced variable is not set and has no default value.
+	Strict bool
+}
+
+var variableNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Expand replaces references to variables in the data with their values.
+//
+// The following syntax is supported:
+//   - ${VAR} is replaced with the value of VAR, or an empty string if it's not set.
+//   - ${VAR:-default} is replaced with the value of VAR, or the default if VAR is not set or empty.
+//   - $$ is replaced with a literal $, so that $${VAR} results in ${VAR}.
+//
+// A $ which is not followed by { or $ is left untouched.
+// In strict mode all references to variables which are not set and have no default are reported.
+func Expand(data []byte, lookup LookupFunc, opts Options) ([]byte, error) {
+	var (
+		buf  bytes.Buffer
+		errs []error
+	)
+	buf.Grow(len(data))
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if c != '$' || i+1 >= len(data) {
+			buf.WriteByte(c)
+			continue
+		}
+		switch data[i+1] {
+		case '$':
+			buf.WriteByte('$')
+			i++
+		case '{':
+			end := bytes.IndexByte(data[i+2:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated variable reference", lineNumber(data, i))
+			}
+			expr := string(data[i+2 : i+2+end])
+			value, err := expandVariable(expr, lookup, opts)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("line %d: %w", lineNumber(data, i), err))
+			}
+			buf.WriteString(value)
+			i += 2 + end
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return buf.Bytes(), nil
+}
+
+func expandVariable(expr string, lookup LookupFunc, opts Options) (string, error) {
+	name, defaultValue, hasDefault := strings.Cut(expr, ":-")
+	if !variableNameRegex.MatchString(name) {
+		return "", fmt.Errorf("invalid variable reference: ${%s}", expr)
+	}
+	value, ok := lookup(name)
+	switch {
+	case hasDefault && value == "":
+		return defaultValue, nil
+	case !ok && opts.Strict:
+		return "", fmt.Errorf("variable %s is not set", name)
+	default:
+		return value, nil
+	}
+}
+
+func lineNumber(data []byte, offset int) int {
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
@@ -0,0 +1,96 @@
+package envexpand
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpand(t *testing.T) {
+	t.Parallel()
+	env := map[string]string{
+		"ENV":     "prod",
+		"CLUSTER": "eu-1",
+		"EMPTY":   "",
+	}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	tests := map[string]struct {
+		in      string
+		opts    Options
+		out     string
+		wantErr string
+	}{
+		"no references": {
+			in:  "kind: SLO\n",
+			out: "kind: SLO\n",
+		},
+		"variables": {
+			in:  `query: up{env="${ENV}", cluster="${CLUSTER}"}`,
+			out: `query: up{env="prod", cluster="eu-1"}`,
+		},
+		"unset variable": {
+			in:  "name: web-${MISSING}",
+			out: "name: web-",
+		},
+		"default of unset variable": {
+			in:  "name: web-${MISSING:-staging}",
+			out: "name: web-staging",
+		},
+		"default of empty variable": {
+			in:  "name: web-${EMPTY:-staging}",
+			out: "name: web-staging",
+		},
+		"default of set variable": {
+			in:  "name: web-${ENV:-staging}",
+			out: "name: web-prod",
+		},
+		"empty default": {
+			in:   "name: web${MISSING:-}",
+			opts: Options{Strict: true},
+			out:  "name: web",
+		},
+		"escaped dollar": {
+			in:  "query: $${ENV} costs $$5",
+			out: "query: ${ENV} costs $5",
+		},
+		"lone dollar": {
+			in:  "query: sum($metric) costs 5$",
+			out: "query: sum($metric) costs 5$",
+		},
+		"strict mode": {
+			in:      "kind: SLO\nname: ${MISSING}\nlabel: ${OTHER}\nenv: ${ENV}",
+			opts:    Options{Strict: true},
+			wantErr: "line 2: variable MISSING is not set\nline 3: variable OTHER is not set",
+		},
+		"strict mode with empty variable": {
+			in:   "name: web${EMPTY}",
+			opts: Options{Strict: true},
+			out:  "name: web",
+		},
+		"unterminated reference": {
+			in:      "kind: SLO\nname: ${ENV",
+			wantErr: "line 2: unterminated variable reference",
+		},
+		"invalid variable name": {
+			in:      "name: ${1ENV}",
+			wantErr: "line 1: invalid variable reference: ${1ENV}",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			out, err := Expand([]byte(tc.in), lookup, tc.opts)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.out, string(out))
+		})
+	}
+}
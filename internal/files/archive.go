@@ -0,0 +1,273 @@
+package files
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+)
+
+// archiveSeparator separates the archive path from the path of a file within the archive,
+// for example: bundle.tar.gz!/slos/web.yaml.
+const archiveSeparator = "!/"
+
+// DefaultMaxArchiveSize is the maximum total size in bytes of files extracted from a single archive,
+// used when [ReadOptions.MaxArchiveSize] is not set.
+const DefaultMaxArchiveSize = 256 << 20
+
+// maxArchiveEntries is the maximum number of entries, including directories, of a single archive.
+const maxArchiveEntries = 10_000
+
+type archiveFormat int
+
+const (
+	archiveFormatTar archiveFormat = iota + 1
+	archiveFormatTarGzip
+	archiveFormatZip
+)
+
+// archive holds the content of all regular files read from an archive.
+type archive struct {
+	names []string
+	files map[string][]byte
+}
+
+// getArchiveFormat returns the format of the archive based on the path extension.
+// For URLs, query and fragment are ignored.
+func getArchiveFormat(p string) (archiveFormat, bool) {
+	if isURL(p) {
+		if u, err := url.Parse(p); err == nil {
+			p = u.Path
+		}
+	}
+	p = strings.ToLower(p)
+	switch {
+	case strings.HasSuffix(p, ".tar"):
+		return archiveFormatTar, true
+	case strings.HasSuffix(p, ".tar.gz"), strings.HasSuffix(p, ".tgz"):
+		return archiveFormatTarGzip, true
+	case strings.HasSuffix(p, ".zip"):
+		return archiveFormatZip, true
+	default:
+		return 0, false
+	}
+}
+
+func isArchive(p string) bool {
+	_, ok := getArchiveFormat(p)
+	return ok
+}
+
+// splitArchivePath splits the path of a file within an archive into the archive path and file name.
+func splitArchivePath(p string) (archivePath, name string, ok bool) {
+	archivePath, name, ok = strings.Cut(p, archiveSeparator)
+	if !ok || !isArchive(archivePath) {
+		return "", "", false
+	}
+	return archivePath, name, true
+}
+
+// discoverArchive returns paths of files within the archive, it treats the archive as a directory.
+func (r *sourceReader) discoverArchive(archivePath string, opts DiscoverOptions) ([]string, error) {
+	a, err := r.loadArchive(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	var discoveredPaths []string
+	for _, name := range a.names {
+		dir := path.Dir(name)
+		// When recursive is false, get only paths for files in the root of the archive.
+		if dir != "." && !opts.Recursive {
+			continue
+		}
+		if dir != "." && archiveDirSkipped(dir, opts) {
+			continue
+		}
+		if opts.matchFile(name, path.Base(name)) {
+			discoveredPaths = append(discoveredPaths, archivePath+archiveSeparator+name)
+		}
+	}
+	return discoveredPaths, nil
+}
+
+// archiveDirSkipped reports whether the directory, or any of its parents, should be skipped.
+func archiveDirSkipped(dir string, opts DiscoverOptions) bool {
+	parts := strings.Split(dir, "/")
+	for i := range parts {
+		if opts.skipDir(path.Join(parts[:i+1]...), parts[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// readArchiveFile reads a single file from an archive.
+func (r *sourceReader) readArchiveFile(archivePath, name string) ([]byte, error) {
+	a, err := r.loadArchive(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := a.files[name]
+	if !ok {
+		return nil, fmt.Errorf("file %s not found in archive %s: %w", name, archivePath, fs.ErrNotExist)
+	}
+	// The archive might have been loaded by a reader with a different limit.
+	if limit := r.opts.MaxFileSize; limit > 0 && int64(len(data)) > limit {
+		return nil, sizeLimitError{source: archivePath + archiveSeparator + name, limit: limit}
+	}
+	return data, nil
+}
+
+// loadArchive reads all files of the archive.
+// Each archive is only read, or downloaded, once per [Session] regardless of how many files are read from it.
+func (r *sourceReader) loadArchive(archivePath string) (*archive, error) {
+	if a, ok := r.session.archive(archivePath); ok {
+		return a, nil
+	}
+	format, ok := getArchiveFormat(archivePath)
+	if !ok {
+		return nil, fmt.Errorf("unsupported archive: %s", archivePath)
+	}
+	data, err := r.readRawSchema(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	maxTotalSize := r.opts.MaxArchiveSize
+	if maxTotalSize <= 0 {
+		maxTotalSize = DefaultMaxArchiveSize
+	}
+	x := &archiveExtractor{
+		archive:      &archive{files: make(map[string][]byte)},
+		archivePath:  archivePath,
+		maxFileSize:  r.opts.MaxFileSize,
+		maxTotalSize: maxTotalSize,
+	}
+	switch format {
+	case archiveFormatTar:
+		err = x.readTar(bytes.NewReader(data))
+	case archiveFormatTarGzip:
+		var gz *gzip.Reader
+		if gz, err = gzip.NewReader(bytes.NewReader(data)); err == nil {
+			err = x.readTar(gz)
+		}
+	case archiveFormatZip:
+		err = x.readZip(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive %s: %w", archivePath, err)
+	}
+	x.archive.sort()
+	r.session.setArchive(archivePath, x.archive)
+	return x.archive, nil
+}
+
+// archiveExtractor reads files of an archive, guarding against decompression bombs.
+// Each file must not exceed maxFileSize, all files together must not exceed maxTotalSize
+// and the archive must not have more than [maxArchiveEntries] entries.
+type archiveExtractor struct {
+	archive      *archive
+	archivePath  string
+	maxFileSize  int64
+	maxTotalSize int64
+	totalSize    int64
+	entries      int
+}
+
+// readTar reads all regular files from the tar archive.
+func (x *archiveExtractor) readTar(r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = x.countEntry(); err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err = x.add(header.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// readZip reads all regular files from the zip archive.
+func (x *archiveExtractor) readZip(data []byte) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		if err = x.countEntry(); err != nil {
+			return err
+		}
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = x.add(f.Name, rc)
+		_ = rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *archiveExtractor) countEntry() error {
+	x.entries++
+	if x.entries > maxArchiveEntries {
+		return fmt.Errorf("archive has more than %d entries", maxArchiveEntries)
+	}
+	return nil
+}
+
+// add reads the file, it never reads past the remaining total size of the archive.
+func (x *archiveExtractor) add(name string, r io.Reader) error {
+	remaining := x.maxTotalSize - x.totalSize
+	data, err := io.ReadAll(io.LimitReader(r, remaining+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > remaining {
+		return sizeLimitError{source: "content of archive " + x.archivePath, limit: x.maxTotalSize}
+	}
+	x.totalSize += int64(len(data))
+	if x.maxFileSize > 0 && int64(len(data)) > x.maxFileSize {
+		return sizeLimitError{source: x.archivePath + archiveSeparator + name, limit: x.maxFileSize}
+	}
+	x.archive.add(name, data)
+	return nil
+}
+
+// add adds the file to the archive, names are cleaned and files with invalid names are skipped.
+func (a *archive) add(name string, data []byte) {
+	name = path.Clean(strings.TrimPrefix(name, "/"))
+	if !fs.ValidPath(name) || name == "." {
+		return
+	}
+	if _, ok := a.files[name]; !ok {
+		a.names = append(a.names, name)
+	}
+	a.files[name] = data
+}
+
+func (a *archive) sort() {
+	slices.Sort(a.names)
+}
@@ -0,0 +1,126 @@
+package files_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenSLO/oslo/internal/files"
+)
+
+const archivedService = `apiVersion: openslo/v1
+kind: Service
+metadata:
+  name: web
+spec: {}
+`
+
+var archivedFiles = map[string]string{
+	"root.yaml":        archivedService,
+	"README.md":        "# Docs",
+	"slos/web.yaml":    archivedService,
+	"slos/bad.yaml":    "apiVersion: openslo/v1\nkind: Unknown\n",
+	".hidden/web.yaml": archivedService,
+}
+
+func TestDiscoverArchives(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	tarGzPath := filepath.Join(dir, "bundle.tar.gz")
+	require.NoError(t, os.WriteFile(tarGzPath, createTarGz(t, archivedFiles), 0o600))
+	zipPath := filepath.Join(dir, "bundle.zip")
+	require.NoError(t, os.WriteFile(zipPath, createZip(t, archivedFiles), 0o600))
+
+	for _, archivePath := range []string{tarGzPath, zipPath} {
+		t.Run(filepath.Base(archivePath), func(t *testing.T) {
+			t.Parallel()
+			paths, err := files.Discover(context.Background(), []string{archivePath}, files.DiscoverOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, []string{archivePath + "!/root.yaml"}, paths)
+
+			paths, err = files.Discover(context.Background(), []string{archivePath}, files.DiscoverOptions{
+				Recursive: true,
+				Exclude:   []string{"bad.yaml"},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []string{archivePath + "!/root.yaml", archivePath + "!/slos/web.yaml"}, paths)
+
+			objects, err := files.ReadObjects(context.Background(), paths, files.ReadOptions{})
+			require.NoError(t, err)
+			assert.Len(t, objects[archivePath+"!/slos/web.yaml"], 1)
+
+			_, err = files.ReadObjects(context.Background(), []string{archivePath + "!/slos/bad.yaml"}, files.ReadOptions{})
+			require.ErrorContains(t, err, "failed to read objects from "+archivePath+"!/slos/bad.yaml")
+		})
+	}
+
+	t.Run("from URL", func(t *testing.T) {
+		t.Parallel()
+		data := createTarGz(t, archivedFiles)
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			_, _ = w.Write(data)
+		}))
+		defer server.Close()
+
+		archiveURL := server.URL + "/bundle.tgz?version=1"
+		readOpts := files.ReadOptions{Session: files.NewSession()}
+		paths, err := files.Discover(context.Background(), []string{archiveURL},
+			files.DiscoverOptions{Recursive: true, Hidden: true, Read: readOpts})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			archiveURL + "!/.hidden/web.yaml",
+			archiveURL + "!/root.yaml",
+			archiveURL + "!/slos/bad.yaml",
+			archiveURL + "!/slos/web.yaml",
+		}, paths)
+		_, err = files.ReadObjects(context.Background(), paths[:2], readOpts)
+		require.NoError(t, err)
+		assert.Equal(t, 1, requests, "archive must be downloaded once per session")
+	})
+}
+
+func createTarGz(t *testing.T, content map[string]string) []byte {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for name, data := range content {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     "./" + name,
+			Mode:     0o600,
+			Size:     int64(len(data)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := tw.Write([]byte(data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func createZip(t *testing.T, content map[string]string) []byte {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for name, data := range content {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
@@ -0,0 +1,184 @@
+package files
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/OpenSLO/go-sdk/pkg/openslo"
+	"github.com/OpenSLO/go-sdk/pkg/openslosdk"
+	"sigs.k8s.io/yaml"
+)
+
+// OriginAnnotation is the annotation key under which [Bundle] records the source of each object.
+const OriginAnnotation = "oslo.openslo.com/origin"
+
+// BundleOptions defines the behavior of [Bundle].
+type BundleOptions struct {
+	// Format is the format of the bundle, YAML stream or JSON list.
+	Format openslosdk.ObjectFormat
+	// Checksum prepends the bundle with a comment containing its SHA-256 checksum.
+	// It is only supported for YAML format.
+	Checksum bool
+}
+
+// kindsOrder lists kinds in the order in which they can reference each other.
+// An object only ever references objects of kinds which precede its own kind.
+var kindsOrder = []openslo.Kind{
+	openslo.KindDataSource,
+	openslo.KindService,
+	openslo.KindAlertNotificationTarget,
+	openslo.KindAlertCondition,
+	openslo.KindAlertPolicy,
+	openslo.KindSLI,
+	openslo.KindSLO,
+}
+
+type bundledObject struct {
+	object  openslo.Object
+	origin  string
+	encoded []byte
+}
+
+// Bundle writes objects read from all sources into a single YAML stream or JSON list.
+// Sources are processed in the provided order, objects which are exact duplicates are written once,
+// while objects sharing version, kind and name, but differing in content, result in an error.
+// Objects are ordered so that dependencies come before their dependents and each object records
+// its source file under [OriginAnnotation].
+// Since openslo/v1alpha objects have no annotations, their origin is only written as a YAML comment.
+func Bundle(
+	out io.Writer,
+	sources []string,
+	objectsPerSource map[string][]openslo.Object,
+	opts BundleOptions,
+) error {
+	if opts.Checksum && opts.Format != openslosdk.FormatYAML {
+		return errors.New("checksum header is only supported for YAML format")
+	}
+	objects, err := collectBundledObjects(sources, objectsPerSource)
+	if err != nil {
+		return err
+	}
+	slices.SortStableFunc(objects, func(a, b bundledObject) int {
+		return slices.Index(kindsOrder, a.object.GetKind()) - slices.Index(kindsOrder, b.object.GetKind())
+	})
+
+	buf := new(bytes.Buffer)
+	switch opts.Format {
+	case openslosdk.FormatYAML:
+		err = writeYAMLBundle(buf, objects)
+	case openslosdk.FormatJSON:
+		err = writeJSONBundle(buf, objects)
+	default:
+		err = fmt.Errorf("unsupported %[1]T: %[1]s", opts.Format)
+	}
+	if err != nil {
+		return err
+	}
+	if opts.Checksum {
+		sum := sha256.Sum256(buf.Bytes())
+		if _, err = fmt.Fprintf(out, "# sha256: %s\n", hex.EncodeToString(sum[:])); err != nil {
+			return err
+		}
+	}
+	_, err = out.Write(buf.Bytes())
+	return err
+}
+
+func collectBundledObjects(sources []string, objectsPerSource map[string][]openslo.Object) ([]bundledObject, error) {
+	var objects []bundledObject
+	seen := make(map[string]bundledObject)
+	for _, src := range sources {
+		for _, object := range objectsPerSource[src] {
+			encoded, err := json.Marshal(object)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode %s from %s: %w", object, src, err)
+			}
+			id := fmt.Sprintf("%s/%s/%s", object.GetVersion(), object.GetKind(), object.GetName())
+			if prev, ok := seen[id]; ok {
+				if bytes.Equal(prev.encoded, encoded) {
+					continue
+				}
+				return nil, fmt.Errorf("conflicting definitions of %s in %s and %s", object, prev.origin, src)
+			}
+			bo := bundledObject{object: object, origin: src, encoded: encoded}
+			seen[id] = bo
+			objects = append(objects, bo)
+		}
+	}
+	return objects, nil
+}
+
+func writeYAMLBundle(out io.Writer, objects []bundledObject) error {
+	for i, bo := range objects {
+		if i > 0 {
+			if _, err := fmt.Fprintln(out, "---"); err != nil {
+				return err
+			}
+		}
+		if bo.object.GetVersion() == openslo.VersionV1alpha {
+			if _, err := fmt.Fprintf(out, "# %s: %s\n", OriginAnnotation, bo.origin); err != nil {
+				return err
+			}
+		}
+		generic, err := annotateOrigin(bo)
+		if err != nil {
+			return err
+		}
+		data, err := yaml.Marshal(generic)
+		if err != nil {
+			return fmt.Errorf("failed to encode object to YAML: %w", err)
+		}
+		if _, err = out.Write(data); err != nil {
+			return fmt.Errorf("failed to write YAML data: %w", err)
+		}
+	}
+	return nil
+}
+
+func writeJSONBundle(out io.Writer, objects []bundledObject) error {
+	list := make([]any, 0, len(objects))
+	for _, bo := range objects {
+		generic, err := annotateOrigin(bo)
+		if err != nil {
+			return err
+		}
+		list = append(list, generic)
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(list); err != nil {
+		return fmt.Errorf("failed to encode objects to JSON: %w", err)
+	}
+	return nil
+}
+
+// annotateOrigin returns a generic representation of the object with its origin recorded in annotations.
+func annotateOrigin(bo bundledObject) (map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(bo.encoded))
+	dec.UseNumber()
+	var generic map[string]any
+	if err := dec.Decode(&generic); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", bo.object, err)
+	}
+	if bo.object.GetVersion() == openslo.VersionV1alpha {
+		return generic, nil
+	}
+	metadata, _ := generic["metadata"].(map[string]any)
+	if metadata == nil {
+		metadata = make(map[string]any)
+		generic["metadata"] = metadata
+	}
+	annotations, _ := metadata["annotations"].(map[string]any)
+	if annotations == nil {
+		annotations = make(map[string]any)
+		metadata["annotations"] = annotations
+	}
+	annotations[OriginAnnotation] = bo.origin
+	return generic, nil
+}
@@ -0,0 +1,93 @@
+package files_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/OpenSLO/go-sdk/pkg/openslo"
+	v1 "github.com/OpenSLO/go-sdk/pkg/openslo/v1"
+	"github.com/OpenSLO/go-sdk/pkg/openslosdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenSLO/oslo/internal/files"
+)
+
+func TestBundle(t *testing.T) {
+	t.Parallel()
+
+	service := v1.NewService(v1.Metadata{Name: "my-service"}, v1.ServiceSpec{})
+	slo := v1.NewSLO(v1.Metadata{Name: "my-slo"}, v1.SLOSpec{Service: "my-service"})
+	dataSource := v1.NewDataSource(v1.Metadata{Name: "my-data-source"}, v1.DataSourceSpec{Type: "Prometheus"})
+
+	t.Run("orders dependencies and drops duplicates", func(t *testing.T) {
+		t.Parallel()
+		out := new(bytes.Buffer)
+		err := files.Bundle(out, []string{"a.yaml", "b.yaml"}, map[string][]openslo.Object{
+			"a.yaml": {slo, service},
+			"b.yaml": {service, dataSource},
+		}, files.BundleOptions{Format: openslosdk.FormatYAML})
+		require.NoError(t, err)
+		assert.Equal(t, `apiVersion: openslo/v1
+kind: DataSource
+metadata:
+  annotations:
+    oslo.openslo.com/origin: b.yaml
+  name: my-data-source
+spec:
+  connectionDetails: null
+  type: Prometheus
+---
+apiVersion: openslo/v1
+kind: Service
+metadata:
+  annotations:
+    oslo.openslo.com/origin: a.yaml
+  name: my-service
+spec: {}
+---
+apiVersion: openslo/v1
+kind: SLO
+metadata:
+  annotations:
+    oslo.openslo.com/origin: a.yaml
+  name: my-slo
+spec:
+  budgetingMethod: ""
+  objectives: null
+  service: my-service
+`, out.String())
+	})
+
+	t.Run("conflicting definitions", func(t *testing.T) {
+		t.Parallel()
+		otherService := v1.NewService(
+			v1.Metadata{Name: "my-service"},
+			v1.ServiceSpec{Description: "different"},
+		)
+		err := files.Bundle(new(bytes.Buffer), []string{"a.yaml", "b.yaml"}, map[string][]openslo.Object{
+			"a.yaml": {service},
+			"b.yaml": {otherService},
+		}, files.BundleOptions{Format: openslosdk.FormatJSON})
+		require.ErrorContains(t, err, "conflicting definitions of v1.Service 'my-service' in a.yaml and b.yaml")
+	})
+
+	t.Run("checksum header", func(t *testing.T) {
+		t.Parallel()
+		out := new(bytes.Buffer)
+		err := files.Bundle(out, []string{"a.yaml"}, map[string][]openslo.Object{
+			"a.yaml": {service},
+		}, files.BundleOptions{Format: openslosdk.FormatYAML, Checksum: true})
+		require.NoError(t, err)
+		assert.Regexp(t, `^# sha256: [0-9a-f]{64}\napiVersion: openslo/v1\n`, out.String())
+	})
+
+	t.Run("checksum header is not supported for JSON", func(t *testing.T) {
+		t.Parallel()
+		err := files.Bundle(new(bytes.Buffer), nil, nil, files.BundleOptions{
+			Format:   openslosdk.FormatJSON,
+			Checksum: true,
+		})
+		require.Error(t, err)
+	})
+}
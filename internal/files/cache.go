@@ -0,0 +1,27 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// cacheDirEnv is the environment variable which overrides the default cache directory.
+const cacheDirEnv = "OSLO_CACHE_DIR"
+
+// cacheDir returns the path of the named directory within the oslo cache directory and creates it.
+// The cache is stored in the user cache directory, unless overridden with OSLO_CACHE_DIR.
+func cacheDir(name string) (string, error) {
+	root := os.Getenv(cacheDirEnv)
+	if root == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		root = filepath.Join(userCacheDir, "oslo")
+	}
+	dir := filepath.Join(root, name)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
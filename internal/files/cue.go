@@ -0,0 +1,161 @@
+package files
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/cuecontext"
+	cueerrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/load"
+	"cuelang.org/go/cue/parser"
+)
+
+// cueModDir is the directory of a CUE module with its dependencies, it's never discovered.
+const cueModDir = "cue.mod"
+
+// evaluateCUE evaluates the CUE source into a JSON list of objects.
+// If a local source declares a package, all files of the package in its directory are evaluated together,
+// along with packages imported from the CUE module the source belongs to.
+// The value must be concrete and it's either an object, a list of objects or a struct
+// whose fields are objects or lists of objects.
+func evaluateCUE(source string, content []byte) ([]byte, error) {
+	ctx := cuecontext.New()
+	var value cue.Value
+	if pkg := cuePackageName(source, content); pkg != "" && !isURL(source) && !isStdin(source) {
+		inst, err := loadCUEPackage(source, pkg, content)
+		if err != nil {
+			return nil, formatCUEError(err)
+		}
+		value = ctx.BuildInstance(inst)
+	} else {
+		value = ctx.CompileBytes(content, cue.Filename(source))
+	}
+	if err := value.Validate(cue.Concrete(true)); err != nil {
+		return nil, formatCUEError(err)
+	}
+	objects, err := collectCUEObjects(value)
+	if err != nil {
+		return nil, formatCUEError(err)
+	}
+	return json.Marshal(objects)
+}
+
+// loadCUEPackage loads the package from the directory of the source.
+// The already read content of the source overrides the file on disk.
+func loadCUEPackage(source, pkg string, content []byte) (*build.Instance, error) {
+	abs, err := filepath.Abs(source)
+	if err != nil {
+		return nil, err
+	}
+	instances := load.Instances([]string{"."}, &load.Config{
+		Dir:     filepath.Dir(abs),
+		Package: pkg,
+		Overlay: map[string]load.Source{abs: load.FromBytes(content)},
+	})
+	if len(instances) != 1 {
+		return nil, fmt.Errorf("expected a single CUE instance, got %d", len(instances))
+	}
+	if err = instances[0].Err; err != nil {
+		return nil, err
+	}
+	return instances[0], nil
+}
+
+// collectCUEObjects returns JSON encoded objects found in the value.
+// Structs which are not objects and lists are searched recursively, other values are ignored.
+func collectCUEObjects(value cue.Value) ([]json.RawMessage, error) {
+	switch value.IncompleteKind() {
+	case cue.StructKind, cue.ListKind:
+	default:
+		return nil, errors.New("CUE value must be an object, a list of objects or a struct of objects")
+	}
+	var objects []json.RawMessage
+	err := walkCUEObjects(value, func(object cue.Value) error {
+		data, err := object.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, data)
+		return nil
+	})
+	return objects, err
+}
+
+func walkCUEObjects(value cue.Value, fn func(object cue.Value) error) error {
+	var (
+		iter *cue.Iterator
+		err  error
+	)
+	switch value.IncompleteKind() {
+	case cue.StructKind:
+		if isCUEObject(value) {
+			return fn(value)
+		}
+		iter, err = value.Fields()
+	case cue.ListKind:
+		var list cue.Iterator
+		list, err = value.List()
+		iter = &list
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for iter.Next() {
+		if err = walkCUEObjects(iter.Value(), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isCUEObject reports whether the struct value looks like an OpenSLO object.
+func isCUEObject(value cue.Value) bool {
+	return value.LookupPath(cue.ParsePath("apiVersion")).Exists() &&
+		value.LookupPath(cue.ParsePath("kind")).Exists()
+}
+
+// cuePackageName returns the name of the package declared by the CUE source, if any.
+func cuePackageName(source string, content []byte) string {
+	f, err := parser.ParseFile(source, content, parser.PackageClauseOnly)
+	if err != nil {
+		return ""
+	}
+	return f.PackageName()
+}
+
+// cuePackageKey returns the key identifying the package of a local CUE file,
+// all files of a package are evaluated together, so only one of them has to be read.
+func cuePackageKey(path string) (string, bool) {
+	if !strings.EqualFold(filepath.Ext(path), ".cue") || isStdin(path) || isURL(path) {
+		return "", false
+	}
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return "", false
+	}
+	pkg := cuePackageName(path, content)
+	if pkg == "" {
+		return "", false
+	}
+	dir, err := realPath(filepath.Dir(path))
+	if err != nil {
+		return "", false
+	}
+	return dir + ":" + pkg, true
+}
+
+// formatCUEError includes positions of all errors in the message.
+func formatCUEError(err error) error {
+	var buf bytes.Buffer
+	cueerrors.Print(&buf, err, nil)
+	return errors.New(strings.TrimSpace(buf.String()))
+}
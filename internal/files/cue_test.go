@@ -0,0 +1,56 @@
+package files_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenSLO/oslo/internal/files"
+)
+
+func TestReadObjects_CUE(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		source  string
+		paths   []string
+		names   []string
+		wantErr string
+	}{
+		"single file": {
+			source: "testdata/cue/single.cue",
+			paths:  []string{"testdata/cue/single.cue"},
+			names:  []string{"web-prod", "api-prod"},
+		},
+		"package is evaluated once": {
+			source: "testdata/cue/pkg",
+			paths:  []string{"testdata/cue/pkg/services.cue"},
+			names:  []string{"api", "web", "prometheus"},
+		},
+		"incomplete value": {
+			source:  "testdata/cue/invalid",
+			wantErr: "metadata.name: incomplete value string:\n    testdata/cue/invalid/incomplete.cue:3:17",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			paths, err := files.Discover(context.Background(), []string{tc.source}, files.DiscoverOptions{})
+			require.NoError(t, err)
+			objects, err := files.ReadObjects(context.Background(), paths, files.ReadOptions{})
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, "failed to evaluate CUE")
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.paths, paths)
+			var names []string
+			for _, o := range objects[paths[0]] {
+				names = append(names, o.GetName())
+			}
+			assert.Equal(t, tc.names, names)
+		})
+	}
+}
@@ -1,18 +1,69 @@
 package files
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io/fs"
 	"os"
-	"path"
 	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
 )
 
+// DefaultExtensions lists extensions of files discovered in directories when no include patterns are set.
+// Jsonnet libraries (.libsonnet) are not listed, as they're meant to be imported rather than evaluated on their own.
+var DefaultExtensions = []string{".yaml", ".yml", ".json", ".ndjson", ".jsonl", ".jsonnet", ".cue"}
+
+// DiscoverOptions defines the behavior of [Discover].
+type DiscoverOptions struct {
+	// Recursive traverses all subdirectories of the provided directories.
+	Recursive bool
+	// Include lists glob patterns of files discovered in directories.
+	// When empty, files with one of the [DefaultExtensions] are discovered.
+	Include []string
+	// Exclude lists glob patterns of files and directories skipped in directories.
+	Exclude []string
+	// Hidden enables discovering files and directories whose names start with a dot.
+	Hidden bool
+	// GitIgnore enables honoring .gitignore files in addition to .osloignore files.
+	GitIgnore bool
+	// Read defines how archives are read.
+	Read ReadOptions
+	// FollowSymlinks enables traversing symbolic links to directories.
+	// Each directory and file is discovered once, even if it's reachable through multiple links,
+	// while discovered paths keep pointing to the links rather than resolved locations.
+	FollowSymlinks bool
+}
+
 // Discover returns all file paths that come from file paths provided as the argument.
 // Return directly if they are standard files. For directories list all files available in its
-// root or recursively traverse all subdirectories and find files in them when the argument recursive
+// root or recursively traverse all subdirectories and find files in them when [DiscoverOptions.Recursive]
 // is true. For "-" path that indicates standard input or starts with http:// or https://,
 // it returns the path directly in the same way as other paths.
-func Discover(filePaths []string, recursive bool) ([]string, error) { //nolint:cyclop
+// Paths containing glob patterns, like slos/**/*.yaml, are expanded before any other processing,
+// each of the matches is then treated as if it was provided directly.
+// Every source is returned once, even if it was provided multiple times.
+//
+// Archives (.tar, .tar.gz, .tgz and .zip), both local and downloaded from URLs, are treated as directories.
+// Paths of files within them are separated from the archive path with "!/", e.g. bundle.tar.gz!/slos/web.yaml.
+//
+// Sources in git::<repository>[//<subdirectory>][?ref=<ref>] format are cloned, or fetched,
+// into the local cache and the checked out subdirectory is discovered as any other local path.
+//
+// Files found in directories are filtered with [DiscoverOptions.Include] and [DiscoverOptions.Exclude]
+// patterns, which are matched against the path relative to the directory or, if the pattern
+// contains no separator, the file name. Hidden files and directories are skipped
+// unless [DiscoverOptions.Hidden] is set.
+// Directories may contain .osloignore files, which follow gitignore format,
+// listing files and directories to skip. They apply to the directory they're in and all of its subdirectories.
+func Discover(ctx context.Context, filePaths []string, opts DiscoverOptions) ([]string, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	r := newSourceReader(ctx, opts.Read)
 	var discoveredPaths []string
 	for _, p := range filePaths {
 		// Indicates that a file should be read from standard input.
@@ -22,50 +73,311 @@ func Discover(filePaths []string, recursive bool) ([]string, error) { //nolint:c
 			discoveredPaths = append(discoveredPaths, p)
 			continue
 		}
+		// Repository is checked out to the local cache and the requested directory is discovered.
+		if isGitSource(p) {
+			dir, err := checkoutGitSource(ctx, p, opts.Read)
+			if err != nil {
+				return nil, err
+			}
+			paths, err := r.discoverPath(dir, opts)
+			if err != nil {
+				return nil, err
+			}
+			discoveredPaths = append(discoveredPaths, paths...)
+			continue
+		}
 		// Content should be downloaded from this URL and treated as others.
 		if isURL(p) {
+			// Archives are treated as directories, regardless if they're local files or URLs.
+			if isArchive(p) {
+				paths, err := r.discoverArchive(p, opts)
+				if err != nil {
+					return nil, err
+				}
+				discoveredPaths = append(discoveredPaths, paths...)
+				continue
+			}
 			discoveredPaths = append(discoveredPaths, p)
 			continue
 		}
 
-		// When path is valid and it's not a directory, use it directly.
-		fInfo, err := os.Stat(p)
+		if isGlobPattern(p) {
+			matches, err := expandGlobPattern(p, opts.Hidden)
+			if err != nil {
+				return nil, err
+			}
+			for _, match := range matches {
+				paths, err := r.discoverPath(match, opts)
+				if err != nil {
+					return nil, err
+				}
+				discoveredPaths = append(discoveredPaths, paths...)
+			}
+			continue
+		}
+
+		paths, err := r.discoverPath(p, opts)
 		if err != nil {
 			return nil, err
 		}
-		if !fInfo.IsDir() {
-			discoveredPaths = append(discoveredPaths, p)
+		discoveredPaths = append(discoveredPaths, paths...)
+	}
+	return deduplicatePaths(discoveredPaths), nil
+}
+
+// deduplicatePaths removes paths which point to the same source, keeping the first occurrence.
+// The same file may be provided multiple times, either directly or through overlapping directories
+// and glob patterns, like dir/** which matches both directories and the files within them.
+// Files are compared by their real paths, so that files reached through symbolic links are kept only once.
+// Files of the same CUE package are evaluated together, thus only the first of them is kept.
+func deduplicatePaths(paths []string) []string {
+	seen := make(map[string]bool, len(paths))
+	deduplicated := make([]string, 0, len(paths))
+	for _, p := range paths {
+		key := p
+		if pkgKey, ok := cuePackageKey(p); ok {
+			key = pkgKey
+		} else if !isStdin(p) && !isURL(p) {
+			if resolved, err := realPath(p); err == nil {
+				key = resolved
+			}
+		}
+		if seen[key] {
 			continue
 		}
+		seen[key] = true
+		deduplicated = append(deduplicated, p)
+	}
+	return deduplicated
+}
+
+// discoverPath returns all file paths for a single file or directory path.
+func (r *sourceReader) discoverPath(p string, opts DiscoverOptions) ([]string, error) {
+	// When path is valid and it's not a directory, use it directly.
+	fInfo, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	if !fInfo.IsDir() {
+		if isArchive(p) {
+			return r.discoverArchive(p, opts)
+		}
+		return []string{p}, nil
+	}
+
+	return walkDir(p, opts)
+}
 
-		// When recursive is true and the path is a directory,
-		// discover all files in it and its subdirectories.
-		if recursive {
-			if walkErr := filepath.Walk(p, func(path string, info fs.FileInfo, err error) error {
+// walkDir returns paths of files found in the directory. It only lists the root of the directory
+// unless [DiscoverOptions.Recursive] is true, in which case all subdirectories are traversed as well.
+func walkDir(root string, opts DiscoverOptions) ([]string, error) {
+	w := dirWalker{
+		opts:            opts,
+		ignoreFileNames: []string{osloIgnoreFileName},
+		visitedDirs:     make(map[string]bool),
+		seenFiles:       make(map[string]bool),
+	}
+	if opts.GitIgnore {
+		w.ignoreFileNames = append(w.ignoreFileNames, gitIgnoreFileName)
+	}
+	if opts.FollowSymlinks {
+		realRoot, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			return nil, err
+		}
+		w.visitedDirs[realRoot] = true
+	}
+	if err := w.walk(root, "", &ignoreMatcher{}); err != nil {
+		return nil, err
+	}
+	return w.discoveredPaths, nil
+}
+
+type dirWalker struct {
+	opts            DiscoverOptions
+	ignoreFileNames []string
+	discoveredPaths []string
+	// visitedDirs and seenFiles hold real paths of traversed directories and discovered files.
+	// They're only populated when following symbolic links.
+	visitedDirs map[string]bool
+	seenFiles   map[string]bool
+}
+
+func (w *dirWalker) walk(dir, rel string, ignore *ignoreMatcher) error {
+	ignore, err := ignore.withDir(dir, rel, w.ignoreFileNames)
+	if err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		entryPath := filepath.Join(dir, e.Name())
+		entryRel := filepath.Join(rel, e.Name())
+		mode := e.Type()
+		if mode&fs.ModeSymlink != 0 {
+			if mode, err = w.resolveSymlink(entryPath, entryRel, e.Name(), ignore); err != nil {
+				return err
+			}
+		}
+		switch {
+		case mode.IsDir():
+			if !w.matchDir(entryRel, e.Name(), ignore) {
+				continue
+			}
+			if w.opts.FollowSymlinks {
+				// Directory reachable through multiple links or forming a cycle is only traversed once.
+				realPath, err := filepath.EvalSymlinks(entryPath)
 				if err != nil {
 					return err
 				}
-				if !info.IsDir() {
-					discoveredPaths = append(discoveredPaths, path)
+				if w.visitedDirs[realPath] {
+					continue
 				}
-				return nil
-			}); walkErr != nil {
-				return nil, walkErr
+				w.visitedDirs[realPath] = true
 			}
-			continue
+			if err = w.walk(entryPath, entryRel, ignore); err != nil {
+				return err
+			}
+		case mode.IsRegular():
+			if !w.matchFile(entryRel, e.Name(), ignore) {
+				continue
+			}
+			if w.opts.FollowSymlinks {
+				realPath, err := filepath.EvalSymlinks(entryPath)
+				if err != nil {
+					return err
+				}
+				if w.seenFiles[realPath] {
+					continue
+				}
+				w.seenFiles[realPath] = true
+			}
+			w.discoveredPaths = append(w.discoveredPaths, entryPath)
 		}
+	}
+	return nil
+}
 
-		// When recursive is false and the path is a directory,
-		// get only paths for files in the root of it.
-		entries, err := os.ReadDir(p)
-		if err != nil {
-			return nil, err
+// matchDir reports whether the directory should be traversed.
+// When recursive is false, only files in the root of the directory are discovered.
+func (w *dirWalker) matchDir(rel, name string, ignore *ignoreMatcher) bool {
+	return w.opts.Recursive && !w.opts.skipDir(rel, name) && !ignore.ignored(rel, true)
+}
+
+// matchFile reports whether the file should be discovered.
+func (w *dirWalker) matchFile(rel, name string, ignore *ignoreMatcher) bool {
+	return w.opts.matchFile(rel, name) && !ignore.ignored(rel, false)
+}
+
+// resolveSymlink returns the type of the file the link points to.
+// The link is only resolved if it passes the filters of the type it may point to,
+// links to directories are only considered when following symbolic links, otherwise they're skipped.
+// Links which are skipped, including dangling ones, are reported as [fs.ModeSymlink].
+func (w *dirWalker) resolveSymlink(path, rel, name string, ignore *ignoreMatcher) (fs.FileMode, error) {
+	asFile := w.matchFile(rel, name, ignore)
+	asDir := w.opts.FollowSymlinks && w.matchDir(rel, name, ignore)
+	if !asFile && !asDir {
+		return fs.ModeSymlink, nil
+	}
+	info, err := os.Stat(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return fs.ModeSymlink, nil
+	case err != nil:
+		return 0, fmt.Errorf("failed to resolve symbolic link: %w", err)
+	case info.IsDir() && !asDir, !info.IsDir() && !asFile:
+		return fs.ModeSymlink, nil
+	default:
+		return info.Mode().Type(), nil
+	}
+}
+
+func (o DiscoverOptions) validate() error {
+	for _, pattern := range slices.Concat(o.Include, o.Exclude) {
+		if !doublestar.ValidatePattern(filepath.ToSlash(pattern)) {
+			return fmt.Errorf("invalid glob pattern %s: %w", pattern, doublestar.ErrBadPattern)
 		}
-		for _, e := range entries {
-			if !e.IsDir() {
-				discoveredPaths = append(discoveredPaths, path.Join(p, e.Name()))
-			}
+	}
+	return nil
+}
+
+// skipDir reports whether the directory found while traversing should be skipped.
+func (o DiscoverOptions) skipDir(rel, name string) bool {
+	if name == cueModDir {
+		return true
+	}
+	if isHidden(name) && !o.Hidden {
+		return true
+	}
+	return matchAnyPattern(o.Exclude, rel, name)
+}
+
+// matchFile reports whether the file found in a directory should be discovered.
+func (o DiscoverOptions) matchFile(rel, name string) bool {
+	if isHidden(name) && !o.Hidden {
+		return false
+	}
+	if matchAnyPattern(o.Exclude, rel, name) {
+		return false
+	}
+	if len(o.Include) > 0 {
+		return matchAnyPattern(o.Include, rel, name)
+	}
+	return slices.Contains(DefaultExtensions, strings.ToLower(filepath.Ext(name)))
+}
+
+// matchAnyPattern reports whether any of the patterns matches the relative path or,
+// for patterns without a separator, the name.
+func matchAnyPattern(patterns []string, rel, name string) bool {
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range patterns {
+		pattern = filepath.ToSlash(pattern)
+		subject := rel
+		if !strings.Contains(pattern, "/") {
+			subject = name
+		}
+		if ok, _ := doublestar.Match(pattern, subject); ok {
+			return true
 		}
 	}
-	return discoveredPaths, nil
+	return false
+}
+
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".") && name != "." && name != ".."
+}
+
+// isGlobPattern reports whether the path is a glob pattern.
+// Paths which exist on the filesystem are never treated as patterns,
+// even if they contain glob meta characters.
+func isGlobPattern(p string) bool {
+	if !strings.ContainsAny(p, "*?[{") {
+		return false
+	}
+	_, err := os.Stat(p)
+	return err != nil
+}
+
+// expandGlobPattern returns deduplicated and sorted paths matching the doublestar glob pattern.
+// Unless hidden is true, wildcards do not match files and directories whose names start with a dot.
+// It is an error if the pattern does not match any path.
+func expandGlobPattern(pattern string, hidden bool) ([]string, error) {
+	if !doublestar.ValidatePathPattern(pattern) {
+		return nil, fmt.Errorf("invalid glob pattern %s: %w", pattern, doublestar.ErrBadPattern)
+	}
+	var globOpts []doublestar.GlobOption
+	if !hidden {
+		globOpts = append(globOpts, doublestar.WithNoHidden())
+	}
+	matches, err := doublestar.FilepathGlob(pattern, globOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand glob pattern %s: %w", pattern, err)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("glob pattern %s did not match any files: %w", pattern, fs.ErrNotExist)
+	}
+	slices.Sort(matches)
+	return slices.Compact(matches), nil
 }
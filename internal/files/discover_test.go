@@ -1,9 +1,13 @@
 package files_test
 
 import (
+	"context"
 	"io/fs"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/stretchr/testify/require"
 
 	"github.com/OpenSLO/oslo/internal/files"
@@ -17,6 +21,7 @@ func TestDiscoverFilePaths(t *testing.T) {
 		name          string
 		filePaths     []string
 		recursive     bool
+		opts          files.DiscoverOptions
 		want          []string
 		expectedError error
 	}{
@@ -82,14 +87,201 @@ func TestDiscoverFilePaths(t *testing.T) {
 				"https://example.com/file-2",
 			},
 		},
+		{
+			name:      "glob pattern",
+			filePaths: []string{"testdata/discover/**/*.yml"},
+			recursive: false,
+			want: []string{
+				"testdata/discover/a/a1.yml", "testdata/discover/a/a2.yml",
+				"testdata/discover/a/b/b1.yml", "testdata/discover/a/b/b2.yml",
+				"testdata/discover/aa/aa1.yml",
+				"testdata/discover/x.yml",
+			},
+		},
+		{
+			name:      "glob pattern matching directories",
+			filePaths: []string{"testdata/discover/a*"},
+			recursive: false,
+			want: []string{
+				"testdata/discover/a/a1.yml", "testdata/discover/a/a2.yml",
+				"testdata/discover/aa/aa1.yml",
+			},
+		},
+		{
+			name:      "overlapping glob pattern matches",
+			filePaths: []string{"testdata/discover/a/**"},
+			recursive: true,
+			want: []string{
+				"testdata/discover/a/a1.yml", "testdata/discover/a/a2.yml",
+				"testdata/discover/a/b/b1.yml", "testdata/discover/a/b/b2.yml",
+			},
+		},
+		{
+			name:          "glob pattern without matches",
+			filePaths:     []string{"testdata/discover/**/*.json"},
+			recursive:     false,
+			expectedError: fs.ErrNotExist,
+		},
+		{
+			name:      "include patterns",
+			filePaths: []string{"testdata/discover"},
+			recursive: true,
+			opts:      files.DiscoverOptions{Include: []string{"*.md", "a/b/*"}},
+			want: []string{
+				"testdata/discover/README.md",
+				"testdata/discover/a/b/b1.yml", "testdata/discover/a/b/b2.yml",
+			},
+		},
+		{
+			name:      "exclude patterns",
+			filePaths: []string{"testdata/discover"},
+			recursive: true,
+			opts:      files.DiscoverOptions{Exclude: []string{"b", "a?1.yml", "x.*"}},
+			want: []string{
+				"testdata/discover/a/a1.yml", "testdata/discover/a/a2.yml",
+				"testdata/discover/y.yaml",
+			},
+		},
+		{
+			name:      "hidden files and directories",
+			filePaths: []string{"testdata/discover"},
+			recursive: true,
+			opts:      files.DiscoverOptions{Hidden: true},
+			want: []string{
+				"testdata/discover/.hidden/h1.yml",
+				"testdata/discover/a/.a3.yml",
+				"testdata/discover/a/a1.yml", "testdata/discover/a/a2.yml",
+				"testdata/discover/a/b/b1.yml", "testdata/discover/a/b/b2.yml",
+				"testdata/discover/aa/aa1.yml",
+				"testdata/discover/x.yml", "testdata/discover/y.yaml",
+			},
+		},
+		{
+			name:      "filters do not apply to explicit files",
+			filePaths: []string{"testdata/discover/README.md", "testdata/discover/a/.a3.yml"},
+			recursive: true,
+			opts:      files.DiscoverOptions{Exclude: []string{"*"}},
+			want:      []string{"testdata/discover/README.md", "testdata/discover/a/.a3.yml"},
+		},
+		{
+			name:          "invalid pattern",
+			filePaths:     []string{"testdata/discover"},
+			opts:          files.DiscoverOptions{Include: []string{"[a"}},
+			expectedError: doublestar.ErrBadPattern,
+		},
+		{
+			name:      "osloignore files",
+			filePaths: []string{"testdata/ignore"},
+			recursive: true,
+			want: []string{
+				"testdata/ignore/a.yaml",
+				"testdata/ignore/keep.invalid.yaml",
+				"testdata/ignore/nested/broken.invalid.yaml",
+				"testdata/ignore/nested/root-only.yaml",
+			},
+		},
+		{
+			name:      "osloignore files without recursive",
+			filePaths: []string{"testdata/ignore"},
+			recursive: false,
+			want: []string{
+				"testdata/ignore/a.yaml",
+				"testdata/ignore/keep.invalid.yaml",
+			},
+		},
+		{
+			name: "duplicated paths",
+			filePaths: []string{
+				"testdata/discover/x.yml", "./testdata/discover/x.yml", "testdata/discover",
+				"-", "-", "https://example.com/file-1", "https://example.com/file-1",
+			},
+			recursive: false,
+			want: []string{
+				"testdata/discover/x.yml", "testdata/discover/y.yaml",
+				"-", "https://example.com/file-1",
+			},
+		},
 	}
 	for _, tC := range testCases {
 		tC := tC
 		t.Run(tC.name, func(t *testing.T) {
 			t.Parallel()
-			res, err := files.Discover(tC.filePaths, tC.recursive)
+			opts := tC.opts
+			opts.Recursive = tC.recursive
+			res, err := files.Discover(context.Background(), tC.filePaths, opts)
 			require.ErrorIs(t, err, tC.expectedError)
 			require.Equal(t, tC.want, res)
 		})
 	}
 }
+
+func TestDiscoverSymlinks(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	for _, d := range []string{"shared", "team-a", "team-b"} {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, d), 0o750))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shared", "ds.yaml"), nil, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "team-a", "slo.yaml"), nil, 0o600))
+	require.NoError(t, os.Symlink(filepath.Join("..", "shared"), filepath.Join(dir, "team-a", "shared")))
+	require.NoError(t, os.Symlink(filepath.Join("..", "shared"), filepath.Join(dir, "team-b", "shared")))
+	require.NoError(t, os.Symlink("..", filepath.Join(dir, "team-b", "loop")))
+
+	t.Run("symlinked directories are skipped by default", func(t *testing.T) {
+		t.Parallel()
+		res, err := files.Discover(context.Background(), []string{filepath.Join(dir, "team-a"), filepath.Join(dir, "team-b")},
+			files.DiscoverOptions{Recursive: true})
+		require.NoError(t, err)
+		require.Equal(t, []string{filepath.Join(dir, "team-a", "slo.yaml")}, res)
+	})
+
+	t.Run("follow symlinks", func(t *testing.T) {
+		t.Parallel()
+		res, err := files.Discover(context.Background(), []string{filepath.Join(dir, "team-a")},
+			files.DiscoverOptions{Recursive: true, FollowSymlinks: true})
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			filepath.Join(dir, "team-a", "shared", "ds.yaml"),
+			filepath.Join(dir, "team-a", "slo.yaml"),
+		}, res)
+	})
+
+	t.Run("follow symlinks with cycles and duplicates", func(t *testing.T) {
+		t.Parallel()
+		res, err := files.Discover(context.Background(), []string{filepath.Join(dir, "team-b")},
+			files.DiscoverOptions{Recursive: true, FollowSymlinks: true})
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			filepath.Join(dir, "team-b", "loop", "shared", "ds.yaml"),
+			filepath.Join(dir, "team-b", "loop", "team-a", "slo.yaml"),
+		}, res)
+	})
+
+	t.Run("multiple roots pointing at the same directory", func(t *testing.T) {
+		t.Parallel()
+		res, err := files.Discover(context.Background(), []string{
+			filepath.Join(dir, "team-a", "shared"),
+			filepath.Join(dir, "team-b", "shared"),
+			filepath.Join(dir, "shared"),
+		}, files.DiscoverOptions{Recursive: true, FollowSymlinks: true})
+		require.NoError(t, err)
+		require.Equal(t, []string{filepath.Join(dir, "team-a", "shared", "ds.yaml")}, res)
+	})
+
+	t.Run("broken symlinks are skipped", func(t *testing.T) {
+		t.Parallel()
+		brokenDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(brokenDir, "slo.yaml"), nil, 0o600))
+		require.NoError(t, os.Symlink("missing.yaml", filepath.Join(brokenDir, "broken.yaml")))
+		require.NoError(t, os.Symlink("missing.md", filepath.Join(brokenDir, "README.md")))
+		require.NoError(t, os.Symlink("missing", filepath.Join(brokenDir, "dir")))
+		for _, opts := range []files.DiscoverOptions{
+			{},
+			{Recursive: true, FollowSymlinks: true},
+		} {
+			res, err := files.Discover(context.Background(), []string{brokenDir}, opts)
+			require.NoError(t, err)
+			require.Equal(t, []string{filepath.Join(brokenDir, "slo.yaml")}, res)
+		}
+	})
+}
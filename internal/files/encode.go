@@ -0,0 +1,36 @@
+package files
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/OpenSLO/go-sdk/pkg/openslo"
+	"github.com/OpenSLO/go-sdk/pkg/openslosdk"
+	"sigs.k8s.io/yaml"
+)
+
+// encodeObject writes a single [openslo.Object] to the provided writer.
+// Unlike [openslosdk.Encode], the object is not wrapped in a list.
+func encodeObject(out io.Writer, format openslosdk.ObjectFormat, object openslo.Object) error {
+	switch format {
+	case openslosdk.FormatYAML:
+		data, err := yaml.Marshal(object)
+		if err != nil {
+			return fmt.Errorf("failed to encode object to YAML: %w", err)
+		}
+		if _, err = out.Write(data); err != nil {
+			return fmt.Errorf("failed to write YAML data: %w", err)
+		}
+		return nil
+	case openslosdk.FormatJSON:
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(object); err != nil {
+			return fmt.Errorf("failed to encode object to JSON: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported %[1]T: %[1]s", format)
+	}
+}
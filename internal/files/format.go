@@ -1,16 +1,51 @@
 package files
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 
 	"github.com/OpenSLO/go-sdk/pkg/openslosdk"
+
+	"github.com/OpenSLO/oslo/internal/normalize"
 )
 
+// ErrDecryptedOutput is returned by [Format] when a source was decrypted and [FormatOptions.AllowDecrypted] is not set.
+// Commands writing objects read from sources listed by [Session.Decrypted] should return it as well.
+var ErrDecryptedOutput = errors.New("refusing to write decrypted content in plaintext")
+
+// FormatOptions defines optional behavior of [Format].
+type FormatOptions struct {
+	// Read defines how sources are read.
+	Read ReadOptions
+	// Normalize rewrites durations, numbers and times to their canonical representation.
+	Normalize bool
+	// Report receives a line for every value rewritten when Normalize is set.
+	// If it's nil, rewrites are not reported.
+	Report io.Writer
+	// AllowDecrypted allows writing the content of sources decrypted with SOPS in plaintext.
+	AllowDecrypted bool
+	// Stream formats sources one document at a time, see [StreamObjects].
+	Stream bool
+}
+
 // Format formats multiple files and writes it to the provided writer, separated with "---".
-func Format(out io.Writer, format openslosdk.ObjectFormat, sources []string) error {
+func Format(
+	ctx context.Context,
+	out io.Writer,
+	format openslosdk.ObjectFormat,
+	sources []string,
+	opts FormatOptions,
+) error {
+	r := newSourceReader(ctx, opts.Read)
 	for i, src := range sources {
-		if err := formatFile(out, format, src); err != nil {
+		formatFunc := r.formatFile
+		if opts.Stream {
+			formatFunc = r.formatStream
+		}
+		if err := formatFunc(out, format, src, opts); err != nil {
 			return err
 		}
 		if i != len(sources)-1 {
@@ -23,15 +58,71 @@ func Format(out io.Writer, format openslosdk.ObjectFormat, sources []string) err
 }
 
 // formatFile formats a single formatFile and writes it to the provided writer.
-func formatFile(out io.Writer, format openslosdk.ObjectFormat, source string) error {
-	content, err := readRawSchema(source)
+func (r *sourceReader) formatFile(
+	out io.Writer,
+	format openslosdk.ObjectFormat,
+	source string,
+	opts FormatOptions,
+) error {
+	content, inputFormat, err := r.readContent(source)
 	if err != nil {
 		return fmt.Errorf("issue reading content: %w", err)
 	}
-	objects, err := readObjectsFromRawData(content)
+	if r.session.isDecrypted(source) && !opts.AllowDecrypted {
+		return fmt.Errorf("%s is encrypted with SOPS: %w", source, ErrDecryptedOutput)
+	}
+	if opts.Normalize {
+		if inputFormat == InputFormatNDJSON {
+			content, err = normalizeNDJSON(content, source, opts.Report)
+		} else {
+			content, err = normalizeContent(content, source, opts.Report, 0)
+		}
+		if err != nil {
+			return fmt.Errorf("issue normalizing content: %w", err)
+		}
+		inputFormat = InputFormatYAML
+	}
+	objects, err := decodeObjects(content, inputFormat)
 	if err != nil {
 		return fmt.Errorf("issue parsing objects: %w", err)
 	}
 
 	return openslosdk.Encode(out, format, objects...)
 }
+
+// normalizeNDJSON normalizes every line of the NDJSON content on its own,
+// so that rewrites are reported with the line numbers of the source. It returns a YAML stream.
+func normalizeNDJSON(content []byte, source string, report io.Writer) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, line := range bytes.Split(content, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		normalized, err := normalizeContent(line, source, report, i)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		buf.WriteString("---\n")
+		buf.Write(normalized)
+	}
+	return buf.Bytes(), nil
+}
+
+// normalizeContent normalizes the content and reports every rewrite to the provided writer.
+// The line offset is added to the reported line numbers, for content which is a part of a larger source.
+func normalizeContent(content []byte, source string, report io.Writer, lineOffset int) ([]byte, error) {
+	normalized, rewrites, err := normalize.Normalize(content)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		return normalized, nil
+	}
+	for _, rw := range rewrites {
+		rw.Line += lineOffset
+		if _, err = fmt.Fprintf(report, "normalized %s: %s\n", source, rw); err != nil {
+			return nil, err
+		}
+	}
+	return normalized, nil
+}
@@ -2,6 +2,7 @@ package files_test
 
 import (
 	"bytes"
+	"context"
 	"path/filepath"
 	"testing"
 
@@ -183,7 +184,7 @@ func TestFormatFiles(t *testing.T) {
 			for i, file := range tc.files {
 				tc.files[i] = filepath.Join("testdata", "format", file)
 			}
-			err := files.Format(out, tc.format, tc.files)
+			err := files.Format(context.Background(), out, tc.format, tc.files, files.FormatOptions{})
 			if tc.wantErr {
 				assert.Error(t, err)
 				return
@@ -0,0 +1,286 @@
+package files
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// gitSourcePrefix prefixes sources which should be read from a git repository,
+// for example: git::https://github.com/org/repo.git//slos?ref=v1.2.0.
+const gitSourcePrefix = "git::"
+
+// gitSource is a parsed git source.
+type gitSource struct {
+	// repo is the URL or local path of the repository.
+	repo string
+	// subdir is the slash separated path of the directory, or file, within the repository.
+	subdir string
+	// ref is the branch, tag or commit to check out, HEAD by default.
+	ref string
+}
+
+// remoteHost returns the host of the repository, unless it's a local one.
+// Both URLs and scp-like [user@]host:path syntax are supported.
+func (s gitSource) remoteHost() (string, bool) {
+	if u, err := url.Parse(s.repo); err == nil && u.Host != "" {
+		return u.Hostname(), true
+	}
+	if isDirectoryPath(s.repo) {
+		return "", false
+	}
+	if before, _, ok := strings.Cut(s.repo, ":"); ok && !strings.Contains(before, "/") {
+		if _, host, ok := strings.Cut(before, "@"); ok {
+			return host, true
+		}
+		return before, true
+	}
+	return "", false
+}
+
+func isGitSource(p string) bool {
+	return strings.HasPrefix(p, gitSourcePrefix)
+}
+
+// parseGitSource parses the source in git::<repo>[//<subdir>][?ref=<ref>] format.
+func parseGitSource(p string) (gitSource, error) {
+	rest := strings.TrimPrefix(p, gitSourcePrefix)
+	src := gitSource{ref: "HEAD"}
+	if i := strings.LastIndex(rest, "?"); i >= 0 {
+		query, err := url.ParseQuery(rest[i+1:])
+		if err != nil {
+			return gitSource{}, fmt.Errorf("invalid git source %s: %w", p, err)
+		}
+		for key := range query {
+			if key != "ref" {
+				return gitSource{}, fmt.Errorf("invalid git source %s: unsupported parameter: %s", p, key)
+			}
+		}
+		if ref := query.Get("ref"); ref != "" {
+			src.ref = ref
+		}
+		rest = rest[:i]
+	}
+	// Skip the URL scheme separator, so that it's not confused with the subdirectory separator.
+	searchFrom := 0
+	if i := strings.Index(rest, "://"); i >= 0 {
+		searchFrom = i + len("://")
+	}
+	if i := strings.Index(rest[searchFrom:], "//"); i >= 0 {
+		src.subdir = strings.Trim(rest[searchFrom+i+2:], "/")
+		rest = rest[:searchFrom+i]
+	}
+	src.repo = rest
+	if src.repo == "" {
+		return gitSource{}, fmt.Errorf("invalid git source %s: repository is required", p)
+	}
+	if src.subdir != "" && !fs.ValidPath(src.subdir) {
+		return gitSource{}, fmt.Errorf("invalid git source %s: invalid subdirectory: %s", p, src.subdir)
+	}
+	if strings.HasPrefix(src.ref, "-") {
+		return gitSource{}, fmt.Errorf("invalid git source %s: invalid ref: %s", p, src.ref)
+	}
+	return src, nil
+}
+
+// checkoutGitSource fetches the repository into the local cache and checks out the requested ref.
+// It returns the local path of the requested subdirectory.
+// Each commit is checked out once, into a directory named after its hash,
+// and a commit which is already present in the cache is used without contacting the remote.
+// In offline mode the remote is never contacted and the ref must be resolvable from the cache.
+// In locked mode the commit recorded in the [Lock] is checked out instead of the ref.
+// Git commands are killed once the context is done.
+func checkoutGitSource(ctx context.Context, p string, opts ReadOptions) (string, error) {
+	src, err := parseGitSource(p)
+	if err != nil {
+		return "", err
+	}
+	if host, ok := src.remoteHost(); ok {
+		if err = opts.checkHost(p, host); err != nil {
+			return "", err
+		}
+	}
+	root, err := cacheDir("git")
+	if err != nil {
+		return "", err
+	}
+	repoHash := sha256.Sum256([]byte(src.repo))
+	repoDir := filepath.Join(root, hex.EncodeToString(repoHash[:8]))
+	mirrorDir := filepath.Join(repoDir, "mirror.git")
+
+	lockedCommit, err := opts.lockedCommit(p)
+	if err != nil {
+		return "", err
+	}
+	if lockedCommit != "" {
+		src.ref = lockedCommit
+	}
+	commit, err := resolveGitCommit(ctx, src, mirrorDir, opts.Offline)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch git source %s: %w", p, err)
+	}
+	checkoutDir := filepath.Join(repoDir, commit)
+	if _, err = os.Stat(checkoutDir); errors.Is(err, fs.ErrNotExist) {
+		if err = exportGitCommit(ctx, mirrorDir, commit, checkoutDir); err != nil {
+			return "", fmt.Errorf("failed to check out git source %s: %w", p, err)
+		}
+	} else if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(checkoutDir, filepath.FromSlash(src.subdir))
+	if opts.Lock != nil {
+		digest, err := hashDir(dir)
+		if err != nil {
+			return "", fmt.Errorf("failed to compute digest of git source %s: %w", p, err)
+		}
+		if err = opts.checkLock(LockedSource{Source: p, Commit: commit, SHA256: digest}); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+var gitCommitHashRegex = regexp.MustCompile(`^[0-9a-f]{40}([0-9a-f]{24})?$`)
+
+// resolveGitCommit returns the hash of the commit the ref points to.
+// The repository is cloned if it's not in the cache yet, otherwise it's fetched,
+// unless the ref is a full commit hash which is already present.
+func resolveGitCommit(ctx context.Context, src gitSource, mirrorDir string, offline bool) (string, error) {
+	_, err := os.Stat(mirrorDir)
+	switch {
+	case offline && errors.Is(err, fs.ErrNotExist):
+		return "", fmt.Errorf("cannot clone %s in offline mode: %w", src.repo, errNotCached)
+	case offline && err == nil:
+		return revParseGitCommit(ctx, mirrorDir, src.ref)
+	case errors.Is(err, fs.ErrNotExist):
+		repo := src.repo
+		if abs, absErr := filepath.Abs(repo); absErr == nil && isDirectoryPath(repo) {
+			repo = abs
+		}
+		if _, err = runGit(ctx, "", "clone", "--quiet", "--mirror", "--", repo, mirrorDir); err != nil {
+			return "", err
+		}
+	case err != nil:
+		return "", err
+	default:
+		if gitCommitHashRegex.MatchString(src.ref) {
+			if commit, err := revParseGitCommit(ctx, mirrorDir, src.ref); err == nil {
+				return commit, nil
+			}
+		}
+		if _, err = runGit(ctx, mirrorDir, "fetch", "--quiet", "--prune", "--tags", "origin"); err != nil {
+			return "", err
+		}
+	}
+	return revParseGitCommit(ctx, mirrorDir, src.ref)
+}
+
+func revParseGitCommit(ctx context.Context, gitDir, ref string) (string, error) {
+	out, err := runGit(ctx, gitDir, "rev-parse", "--verify", "--quiet", "--end-of-options", ref+"^{commit}")
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("ref %s not found", ref)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// exportGitCommit writes all files of the commit to the directory.
+// Files are written to a temporary directory first, so that a partial checkout is never used.
+func exportGitCommit(ctx context.Context, gitDir, commit, dir string) error {
+	out, err := runGit(ctx, gitDir, "archive", "--format=tar", commit)
+	if err != nil {
+		return err
+	}
+	tmpDir, err := os.MkdirTemp(filepath.Dir(dir), ".checkout-")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	if err = extractTar(bytes.NewReader(out), tmpDir); err != nil {
+		return err
+	}
+	return os.Rename(tmpDir, dir)
+}
+
+// extractTar writes regular files and directories from the tar stream to the directory.
+func extractTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.Clean(header.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("invalid file name in archive: %s", header.Name)
+		}
+		target := filepath.Join(dir, name)
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(target, 0o750); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err = os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+			if err != nil {
+				return err
+			}
+			// #nosec G110 -- the archive is produced by git from a trusted, local repository.
+			_, err = io.Copy(f, tr)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func runGit(ctx context.Context, gitDir string, args ...string) ([]byte, error) {
+	subcommand := args[0]
+	if gitDir != "" {
+		args = append([]string{"--git-dir", gitDir}, args...)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	stderr := new(bytes.Buffer)
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		// The process is killed when the context is done, report why instead of the signal.
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("git %s: %w", subcommand, ctxErr)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %w: %s", subcommand, err, msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", subcommand, err)
+	}
+	return out, nil
+}
+
+func isDirectoryPath(p string) bool {
+	fInfo, err := os.Stat(p)
+	return err == nil && fInfo.IsDir()
+}
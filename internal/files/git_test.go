@@ -0,0 +1,189 @@
+package files
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGitSource(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		source  string
+		want    gitSource
+		wantErr bool
+	}{
+		{
+			source: "git::https://github.com/org/repo.git//slos/team?ref=v1.2.0",
+			want:   gitSource{repo: "https://github.com/org/repo.git", subdir: "slos/team", ref: "v1.2.0"},
+		},
+		{
+			source: "git::https://github.com/org/repo.git",
+			want:   gitSource{repo: "https://github.com/org/repo.git", ref: "HEAD"},
+		},
+		{
+			source: "git::/srv/repos/platform.git//datasources",
+			want:   gitSource{repo: "/srv/repos/platform.git", subdir: "datasources", ref: "HEAD"},
+		},
+		{
+			source: "git::file:///srv/repos/platform.git?ref=main",
+			want:   gitSource{repo: "file:///srv/repos/platform.git", ref: "main"},
+		},
+		{source: "git::", wantErr: true},
+		{source: "git::repo.git?branch=main", wantErr: true},
+		{source: "git::repo.git//../etc", wantErr: true},
+		{source: "git::repo.git?ref=--upload-pack=evil", wantErr: true},
+	}
+	for _, tc := range tests {
+		src, err := parseGitSource(tc.source)
+		if tc.wantErr {
+			assert.Error(t, err, tc.source)
+			continue
+		}
+		require.NoError(t, err, tc.source)
+		assert.Equal(t, tc.want, src, tc.source)
+	}
+}
+
+func TestDiscover_GitSource(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv(cacheDirEnv, t.TempDir())
+
+	workDir := t.TempDir()
+	bareDir := filepath.Join(t.TempDir(), "platform.git")
+	git := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	writeFile := func(name string) {
+		t.Helper()
+		p := filepath.Join(workDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o750))
+		require.NoError(t, os.WriteFile(p, []byte(name), 0o600))
+	}
+
+	git(workDir, "init", "--quiet")
+	writeFile("datasources/prometheus.yaml")
+	writeFile("README.md")
+	git(workDir, "add", "-A")
+	git(workDir, "commit", "--quiet", "-m", "initial")
+	git(workDir, "tag", "v1.0.0")
+	writeFile("datasources/graphite.yaml")
+	git(workDir, "add", "-A")
+	git(workDir, "commit", "--quiet", "-m", "second")
+	git(workDir, "clone", "--quiet", "--bare", workDir, bareDir)
+
+	discoverNames := func(source string) []string {
+		t.Helper()
+		paths, err := Discover(context.Background(), []string{source}, DiscoverOptions{Recursive: true})
+		require.NoError(t, err)
+		names := make([]string, 0, len(paths))
+		for _, p := range paths {
+			data, err := os.ReadFile(p)
+			require.NoError(t, err)
+			names = append(names, string(data))
+		}
+		return names
+	}
+
+	assert.Equal(t,
+		[]string{"datasources/prometheus.yaml"},
+		discoverNames("git::"+bareDir+"//datasources?ref=v1.0.0"))
+	assert.Equal(t,
+		[]string{"datasources/graphite.yaml", "datasources/prometheus.yaml"},
+		discoverNames("git::"+bareDir+"//datasources"))
+
+	_, err := Discover(context.Background(), []string{"git::" + bareDir + "?ref=v9.9.9"}, DiscoverOptions{})
+	require.ErrorContains(t, err, "ref v9.9.9 not found")
+
+	t.Run("offline", func(t *testing.T) {
+		offline := DiscoverOptions{Recursive: true, Read: ReadOptions{Offline: true}}
+		paths, err := Discover(context.Background(), []string{"git::" + bareDir + "//datasources?ref=v1.0.0"}, offline)
+		require.NoError(t, err)
+		assert.Len(t, paths, 1)
+
+		git(workDir, "tag", "v2.0.0")
+		git(workDir, "push", "--quiet", bareDir, "v2.0.0")
+		_, err = Discover(context.Background(), []string{"git::" + bareDir + "?ref=v2.0.0"}, offline)
+		require.ErrorContains(t, err, "ref v2.0.0 not found")
+
+		_, err = Discover(context.Background(), []string{"git::" + workDir}, offline)
+		require.ErrorIs(t, err, errNotCached)
+	})
+
+	t.Run("locked", func(t *testing.T) {
+		source := "git::" + bareDir + "//datasources"
+		lock := NewLock()
+		_, err := Discover(context.Background(), []string{source}, DiscoverOptions{Read: ReadOptions{Lock: lock}})
+		require.NoError(t, err)
+		require.Len(t, lock.Sources(), 1)
+		locked := lock.Sources()[0]
+		assert.Regexp(t, gitCommitHashRegex, locked.Commit)
+
+		writeFile("datasources/datadog.yaml")
+		git(workDir, "add", "-A")
+		git(workDir, "commit", "--quiet", "-m", "third")
+		git(workDir, "push", "--quiet", bareDir, "HEAD:refs/heads/"+defaultBranch(t, bareDir))
+
+		lockedOpts := DiscoverOptions{Recursive: true, Read: ReadOptions{Lock: lock, Locked: true}}
+		paths, err := Discover(context.Background(), []string{source}, lockedOpts)
+		require.NoError(t, err)
+		assert.Len(t, paths, 2, "locked commit must be checked out instead of the branch head")
+
+		_, err = Discover(context.Background(), []string{source + "?ref=v1.0.0"}, lockedOpts)
+		require.ErrorContains(t, err, "is not recorded in the lock file")
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		t.Setenv(cacheDirEnv, t.TempDir())
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := Discover(ctx, []string{"git::" + bareDir}, DiscoverOptions{})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func defaultBranch(t *testing.T, gitDir string) string {
+	t.Helper()
+	out, err := exec.Command("git", "--git-dir", gitDir, "symbolic-ref", "--short", "HEAD").Output()
+	require.NoError(t, err)
+	return strings.TrimSpace(string(out))
+}
+
+func TestGitSourceRemoteHost(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		repo   string
+		host   string
+		remote bool
+	}{
+		"https":     {repo: "https://github.com/org/repo.git", host: "github.com", remote: true},
+		"ssh":       {repo: "ssh://git@gitlab.example.com:2222/org/repo.git", host: "gitlab.example.com", remote: true},
+		"scp-like":  {repo: "git@github.com:org/repo.git", host: "github.com", remote: true},
+		"local dir": {repo: "testdata", remote: false},
+		"relative":  {repo: "../repo.git", remote: false},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			host, remote := gitSource{repo: tc.repo}.remoteHost()
+			assert.Equal(t, tc.remote, remote)
+			assert.Equal(t, tc.host, host)
+		})
+	}
+}
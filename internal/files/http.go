@@ -0,0 +1,275 @@
+package files
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// defaultRetryBackoff is used when [HTTPOptions.RetryBackoff] is not set.
+const defaultRetryBackoff = 500 * time.Millisecond
+
+// maxHTTPRedirects is the number of redirects followed, the same as the default of [http.Client].
+const maxHTTPRedirects = 10
+
+// HTTPOptions defines how sources are downloaded from URLs.
+type HTTPOptions struct {
+	// Timeout limits the duration of a single request, zero means no timeout.
+	Timeout time.Duration
+	// Retries is the number of times a request is retried after a network error or a 5xx response.
+	Retries int
+	// RetryBackoff is the wait time before the first retry, it's doubled with each subsequent retry.
+	RetryBackoff time.Duration
+	// Headers are added to every request.
+	Headers http.Header
+	// BearerToken is sent in the Authorization header of every request, if set.
+	BearerToken string
+	// CAFile is the path to PEM encoded certificates trusted in addition to the system ones.
+	CAFile string
+	// CertFile and KeyFile are paths to PEM encoded client certificate and its key, used for mutual TLS.
+	CertFile string
+	KeyFile  string
+	// Proxy is the URL of the proxy used for all requests.
+	// When empty, the proxy is read from HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+	Proxy string
+	// NoProxy is a comma separated list of hosts which are not reached through the Proxy.
+	NoProxy string
+	// InsecureSkipVerify disables verification of server certificates.
+	InsecureSkipVerify bool
+	// MaxResponseSize is the maximum size in bytes of a downloaded file. Zero means no limit.
+	MaxResponseSize int64
+	// Cache enables storing downloaded files in the oslo cache directory.
+	// Cached files are revalidated with the server using ETag and Last-Modified headers.
+	Cache bool
+	// CacheAuthenticated enables the Cache for requests sent with the BearerToken or Headers,
+	// which usually carry credentials. Their responses are cached separately for every set of headers.
+	CacheAuthenticated bool
+}
+
+// authenticated reports whether requests are sent with headers which may carry credentials.
+func (o HTTPOptions) authenticated() bool {
+	return o.BearerToken != "" || len(o.Headers) > 0
+}
+
+// httpStatusError is returned when the server responds with a non-2xx status.
+type httpStatusError struct {
+	url        string
+	status     string
+	statusCode int
+}
+
+func (e httpStatusError) Error() string {
+	return fmt.Sprintf("unexpected response status from %s: %s", e.url, e.status)
+}
+
+func (e httpStatusError) retryable() bool {
+	return e.statusCode >= 500
+}
+
+func newHTTPClient(opts HTTPOptions) (*http.Client, error) {
+	tlsConfig, err := newTLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	proxy := http.ProxyFromEnvironment
+	if opts.Proxy != "" {
+		if _, err = url.Parse(opts.Proxy); err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		proxyFunc := (&httpproxy.Config{
+			HTTPProxy:  opts.Proxy,
+			HTTPSProxy: opts.Proxy,
+			NoProxy:    opts.NoProxy,
+		}).ProxyFunc()
+		proxy = func(req *http.Request) (*url.URL, error) { return proxyFunc(req.URL) }
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	transport.Proxy = proxy
+	return &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: transport,
+	}, nil
+}
+
+func newTLSConfig(opts HTTPOptions) (*tls.Config, error) {
+	// #nosec G402 -- skipping verification must be explicitly requested by the user.
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+	}
+	if opts.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(filepath.Clean(opts.CAFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM encoded certificates found in CA file %s", opts.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	switch {
+	case opts.CertFile != "" && opts.KeyFile != "":
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	case opts.CertFile != "" || opts.KeyFile != "":
+		return nil, errors.New("both client certificate and key files must be provided")
+	}
+	return tlsConfig, nil
+}
+
+// fetchURL downloads the content of the URL, retrying it according to [HTTPOptions].
+// If caching is enabled, the cached response is revalidated with the server and reused if it's still valid.
+// Responses of authenticated requests are cached only if [HTTPOptions.CacheAuthenticated] is set.
+// In offline mode the content is served only from the cache.
+func (r *sourceReader) fetchURL(url string) ([]byte, error) {
+	var (
+		cache  *httpCache
+		cached *httpCacheEntry
+		err    error
+	)
+	cacheKey := httpCacheKey{url: url, header: r.requestHeader()}
+	useCache := r.opts.HTTP.Cache || r.opts.Offline
+	if r.opts.HTTP.authenticated() && !r.opts.HTTP.CacheAuthenticated {
+		useCache = false
+	}
+	if useCache {
+		if cache, err = newHTTPCache(); err != nil {
+			return nil, err
+		}
+		if cached, err = cache.get(cacheKey); err != nil {
+			return nil, fmt.Errorf("failed to read cached response of %s: %w", url, err)
+		}
+	}
+	if err = r.opts.checkURLHost(url); err != nil {
+		return nil, err
+	}
+	if r.opts.Offline {
+		if cached == nil {
+			return nil, fmt.Errorf("cannot download %s in offline mode: %w", url, errNotCached)
+		}
+		r.contentTypes[url] = cached.ContentType
+		r.resolvedURLs[url] = cached.resolvedURL()
+		return cached.body, nil
+	}
+	backoff := r.opts.HTTP.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+	for attempt := 0; ; attempt++ {
+		data, header, err := r.fetchURLOnce(url, cached)
+		if err == nil {
+			if header == nil {
+				r.contentTypes[url] = cached.ContentType
+				return data, nil
+			}
+			r.contentTypes[url] = header.Get("Content-Type")
+			if cache != nil {
+				// The cache is only an optimization, failing to update it must not fail the download.
+				_ = cache.put(cacheKey, r.resolvedURLs[url], header, data)
+			}
+			return data, nil
+		}
+		if attempt >= r.opts.HTTP.Retries || !isRetryableHTTPError(r.ctx, err) {
+			return nil, err
+		}
+		select {
+		case <-r.ctx.Done():
+			return nil, r.ctx.Err()
+		case <-time.After(backoff << attempt):
+		}
+	}
+}
+
+// fetchURLOnce sends a single request for the URL.
+// It returns the response headers only if the body was downloaded,
+// if the cached entry is still valid, its body is returned without the headers.
+func (r *sourceReader) fetchURLOnce(url string, cached *httpCacheEntry) ([]byte, http.Header, error) {
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header = r.requestHeader()
+	if cached != nil {
+		cached.setValidators(req)
+	}
+	client, err := r.httpClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	r.resolvedURLs[url] = resp.Request.URL.String()
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return cached.body, nil, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// Drain the body, so that the connection can be reused by retries.
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil, nil, httpStatusError{url: url, status: resp.Status, statusCode: resp.StatusCode}
+	}
+	maxSize := r.opts.HTTP.MaxResponseSize
+	if maxSize > 0 && resp.ContentLength > maxSize {
+		return nil, nil, sizeLimitError{source: url, limit: maxSize}
+	}
+	data, err := readLimited(resp.Body, maxSize, url)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, resp.Header, nil
+}
+
+// requestHeader returns the headers sent with every request, including the credentials.
+func (r *sourceReader) requestHeader() http.Header {
+	header := make(http.Header, len(r.opts.HTTP.Headers)+1)
+	for name, values := range r.opts.HTTP.Headers {
+		for _, v := range values {
+			header.Add(name, v)
+		}
+	}
+	if r.opts.HTTP.BearerToken != "" {
+		header.Set("Authorization", "Bearer "+r.opts.HTTP.BearerToken)
+	}
+	return header
+}
+
+// isRetryableHTTPError reports whether the request should be retried.
+// Network errors and 5xx responses are retried, unless the context was canceled.
+func isRetryableHTTPError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var (
+		statusErr httpStatusError
+		sizeErr   sizeLimitError
+		hostErr   hostNotAllowedError
+	)
+	switch {
+	case errors.As(err, &statusErr):
+		return statusErr.retryable()
+	case errors.As(err, &sizeErr), errors.As(err, &hostErr):
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,174 @@
+package files
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// errNotCached is returned in offline mode for sources which were never downloaded.
+var errNotCached = errors.New("not found in cache")
+
+// httpCacheEntry is a cached response body along with the validators used to revalidate it.
+type httpCacheEntry struct {
+	URL          string `json:"url"`
+	ResolvedURL  string `json:"resolvedURL,omitempty"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	ContentType  string `json:"contentType,omitempty"`
+
+	body []byte
+}
+
+// httpCacheKey identifies a cached response.
+// Request headers are part of the key, so that responses served to different credentials are never mixed up.
+type httpCacheKey struct {
+	url    string
+	header http.Header
+}
+
+// hash returns the hex encoded hash of the key, the headers are hashed rather than stored,
+// so that credentials never end up in the cache directory.
+func (k httpCacheKey) hash() string {
+	h := sha256.New()
+	_, _ = h.Write([]byte(k.url))
+	for _, name := range slices.Sorted(maps.Keys(k.header)) {
+		_, _ = fmt.Fprintf(h, "\n%s: %s", name, strings.Join(k.header[name], ", "))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// httpCache stores response bodies of URL sources on disk, in the "http" oslo cache directory.
+// Each entry consists of the body file and a JSON metadata file, both named after the [httpCacheKey] hash.
+type httpCache struct {
+	dir string
+}
+
+func newHTTPCache() (*httpCache, error) {
+	dir, err := cacheDir("http")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP cache directory: %w", err)
+	}
+	return &httpCache{dir: dir}, nil
+}
+
+// get returns the cached entry of the key, or nil if there's no such entry.
+func (c *httpCache) get(key httpCacheKey) (*httpCacheEntry, error) {
+	metaPath, bodyPath := c.paths(key)
+	meta, err := os.ReadFile(metaPath) // #nosec G304
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var entry httpCacheEntry
+	if err = json.Unmarshal(meta, &entry); err != nil || entry.URL != key.url {
+		// Treat corrupted entries as missing, they're overwritten by the next download.
+		return nil, nil
+	}
+	if entry.body, err = os.ReadFile(bodyPath); err != nil { // #nosec G304
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// put stores the response body along with its validators and the URL the request was redirected to.
+// The body is written before the metadata, so that an interrupted write never produces a valid entry.
+// Responses which must not be stored, see [isStorable], are not written and remove the previous entry.
+func (c *httpCache) put(key httpCacheKey, resolvedURL string, header http.Header, body []byte) error {
+	metaPath, bodyPath := c.paths(key)
+	_ = os.Remove(metaPath)
+	if !isStorable(header) {
+		_ = os.Remove(bodyPath)
+		return nil
+	}
+	entry := httpCacheEntry{
+		URL:          key.url,
+		ResolvedURL:  resolvedURL,
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+		ContentType:  header.Get("Content-Type"),
+	}
+	meta, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err = writeFileAtomic(bodyPath, body); err != nil {
+		return err
+	}
+	return writeFileAtomic(metaPath, meta)
+}
+
+func (c *httpCache) paths(key httpCacheKey) (metaPath, bodyPath string) {
+	name := key.hash()
+	return filepath.Join(c.dir, name+".json"), filepath.Join(c.dir, name+".body")
+}
+
+// isStorable reports whether the response may be written to the cache.
+// Responses marked with no-store or private Cache-Control directives are not.
+func isStorable(header http.Header) bool {
+	for _, value := range header.Values("Cache-Control") {
+		for directive := range strings.SplitSeq(value, ",") {
+			name, _, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if strings.EqualFold(name, "no-store") || strings.EqualFold(name, "private") {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// resolvedURL returns the URL the request was redirected to, or the requested URL if it wasn't.
+func (e *httpCacheEntry) resolvedURL() string {
+	if e.ResolvedURL != "" {
+		return e.ResolvedURL
+	}
+	return e.URL
+}
+
+// setValidators adds conditional request headers, so that the server
+// responds with 304 Not Modified if the cached entry is still valid.
+func (e *httpCacheEntry) setValidators(req *http.Request) {
+	if e.ETag != "" {
+		req.Header.Set("If-None-Match", e.ETag)
+	}
+	if e.LastModified != "" {
+		req.Header.Set("If-Modified-Since", e.LastModified)
+	}
+}
+
+// writeFileAtomic writes the data to a temporary file first and then renames it,
+// so that concurrent readers never observe partially written files.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err = f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err = os.Rename(f.Name(), path); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package files
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+const (
+	osloIgnoreFileName = ".osloignore"
+	gitIgnoreFileName  = ".gitignore"
+)
+
+// ignoreRule is a single pattern read from an ignore file.
+type ignoreRule struct {
+	// base is the slash separated path of the directory containing the ignore file,
+	// relative to the discovered directory. It's empty for the discovered directory itself.
+	base     string
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// ignoreMatcher matches paths against rules read from ignore files, following gitignore semantics.
+// Rules read from nested directories take precedence over the ones read from their parents
+// and within a single file, the last matching rule wins.
+type ignoreMatcher struct {
+	rules []ignoreRule
+}
+
+// withDir returns a new matcher extended with the rules read from ignore files in the directory.
+// The rel argument is the path of the directory relative to the discovered directory.
+func (m *ignoreMatcher) withDir(dir, rel string, fileNames []string) (*ignoreMatcher, error) {
+	var rules []ignoreRule
+	for _, name := range fileNames {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		fileRules, err := parseIgnoreFile(data, filepath.ToSlash(rel))
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore file %s: %w", filepath.Join(dir, name), err)
+		}
+		rules = append(rules, fileRules...)
+	}
+	if len(rules) == 0 {
+		return m, nil
+	}
+	extended := &ignoreMatcher{rules: make([]ignoreRule, 0, len(m.rules)+len(rules))}
+	extended.rules = append(extended.rules, m.rules...)
+	extended.rules = append(extended.rules, rules...)
+	return extended, nil
+}
+
+// ignored reports whether the path, relative to the discovered directory, is ignored.
+func (m *ignoreMatcher) ignored(rel string, isDir bool) bool {
+	rel = filepath.ToSlash(rel)
+	ignored := false
+	for _, r := range m.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		subject := rel
+		if r.base != "" {
+			var ok bool
+			if subject, ok = strings.CutPrefix(rel, r.base+"/"); !ok {
+				continue
+			}
+		}
+		if !r.anchored {
+			subject = path.Base(subject)
+		}
+		if ok, _ := doublestar.Match(r.pattern, subject); ok {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// parseIgnoreFile parses the content of an ignore file which follows gitignore format.
+func parseIgnoreFile(data []byte, base string) ([]ignoreRule, error) {
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = trimUnescapedTrailingSpaces(line)
+		r := ignoreRule{base: base}
+		switch {
+		case strings.HasPrefix(line, "!"):
+			r.negate = true
+			line = line[1:]
+		case strings.HasPrefix(line, `\!`), strings.HasPrefix(line, `\#`):
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			r.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		if !doublestar.ValidatePattern(line) {
+			return nil, fmt.Errorf("line %d: invalid pattern: %s", lineNum, scanner.Text())
+		}
+		r.pattern = line
+		rules = append(rules, r)
+	}
+	return rules, scanner.Err()
+}
+
+// trimUnescapedTrailingSpaces removes trailing spaces unless they're escaped with a backslash.
+func trimUnescapedTrailingSpaces(line string) string {
+	trimmed := strings.TrimRight(line, " ")
+	if len(trimmed) < len(line) && strings.HasSuffix(trimmed, `\`) {
+		return trimmed[:len(trimmed)-1] + " "
+	}
+	return trimmed
+}
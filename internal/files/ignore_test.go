@@ -0,0 +1,71 @@
+package files
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	t.Parallel()
+	rules, err := parseIgnoreFile([]byte(`# comment
+\#hash.yaml
+generated/
+/root.yaml
+docs/**/*.yaml
+*.yaml
+!keep.yaml
+trailing.yaml\ `), "")
+	require.NoError(t, err)
+	nestedRules, err := parseIgnoreFile([]byte("!nested.yaml\n"), "sub")
+	require.NoError(t, err)
+	m := &ignoreMatcher{rules: append(rules, nestedRules...)}
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{path: "#hash.yaml", ignored: true},
+		{path: "generated", isDir: true, ignored: true},
+		{path: "sub/generated", isDir: true, ignored: true},
+		{path: "generated", isDir: false, ignored: false},
+		{path: "root.yaml", ignored: true},
+		{path: "docs/a/b/c.yaml", ignored: true},
+		{path: "keep.yaml", ignored: false},
+		{path: "sub/keep.yaml", ignored: false},
+		{path: "sub/nested.yaml", ignored: false},
+		{path: "nested.yaml", ignored: true},
+		{path: "trailing.yaml ", ignored: true},
+		{path: "file.json", ignored: false},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.ignored, m.ignored(tc.path, tc.isDir), tc.path)
+	}
+}
+
+func TestIgnoreMatcher_InvalidPattern(t *testing.T) {
+	t.Parallel()
+	_, err := parseIgnoreFile([]byte("ok.yaml\n[invalid\n"), "")
+	require.ErrorContains(t, err, "line 2: invalid pattern: [invalid")
+}
+
+func TestDiscover_GitIgnore(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, gitIgnoreFileName), []byte("generated.yaml\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "generated.yaml"), nil, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "slo.yaml"), nil, 0o600))
+
+	paths, err := Discover(context.Background(), []string{dir}, DiscoverOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "generated.yaml"), filepath.Join(dir, "slo.yaml")}, paths)
+
+	paths, err = Discover(context.Background(), []string{dir}, DiscoverOptions{GitIgnore: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "slo.yaml")}, paths)
+}
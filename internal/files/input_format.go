@@ -0,0 +1,239 @@
+package files
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/OpenSLO/go-sdk/pkg/openslo"
+	"github.com/OpenSLO/go-sdk/pkg/openslosdk"
+)
+
+// InputFormat is the format of the read content.
+type InputFormat string
+
+const (
+	// InputFormatAuto detects the format from the file extension, the HTTP Content-Type header
+	// and finally from the content itself, in that order.
+	InputFormatAuto InputFormat = ""
+	// InputFormatYAML is a stream of YAML documents, each holding an object or a list of objects.
+	InputFormatYAML InputFormat = "yaml"
+	// InputFormatJSON is a JSON object or a list of objects.
+	InputFormatJSON InputFormat = "json"
+	// InputFormatNDJSON is a stream of JSON objects, one per line.
+	InputFormatNDJSON InputFormat = "ndjson"
+	// InputFormatJsonnet is a Jsonnet program which evaluates to a JSON object or a list of objects.
+	InputFormatJsonnet InputFormat = "jsonnet"
+	// InputFormatCUE is a CUE file or package which evaluates to concrete objects.
+	InputFormatCUE InputFormat = "cue"
+)
+
+// ParseInputFormat parses the name of the [InputFormat], "auto" and an empty string mean [InputFormatAuto].
+func ParseInputFormat(s string) (InputFormat, error) {
+	switch f := InputFormat(strings.ToLower(s)); f {
+	case "auto", InputFormatAuto:
+		return InputFormatAuto, nil
+	case InputFormatYAML, InputFormatJSON, InputFormatNDJSON, InputFormatJsonnet, InputFormatCUE:
+		return f, nil
+	default:
+		return "", fmt.Errorf("invalid input format: %s", s)
+	}
+}
+
+// extensionFormats maps file extensions to their formats.
+var extensionFormats = map[string]InputFormat{
+	".yaml":      InputFormatYAML,
+	".yml":       InputFormatYAML,
+	".json":      InputFormatJSON,
+	".ndjson":    InputFormatNDJSON,
+	".jsonl":     InputFormatNDJSON,
+	".jsonnet":   InputFormatJsonnet,
+	".libsonnet": InputFormatJsonnet,
+	".cue":       InputFormatCUE,
+}
+
+// contentTypeFormats maps media types to their formats.
+var contentTypeFormats = map[string]InputFormat{
+	"application/yaml":      InputFormatYAML,
+	"application/x-yaml":    InputFormatYAML,
+	"text/yaml":             InputFormatYAML,
+	"text/x-yaml":           InputFormatYAML,
+	"application/json":      InputFormatJSON,
+	"text/json":             InputFormatJSON,
+	"application/x-ndjson":  InputFormatNDJSON,
+	"application/ndjson":    InputFormatNDJSON,
+	"application/jsonl":     InputFormatNDJSON,
+	"application/x-jsonl":   InputFormatNDJSON,
+	"application/jsonlines": InputFormatNDJSON,
+}
+
+// detectInputFormat returns the format of the source content.
+// The explicitly requested format takes precedence over the file extension,
+// which takes precedence over the HTTP Content-Type header, and the content is sniffed as the last resort.
+func (r *sourceReader) detectInputFormat(source string, data []byte) InputFormat {
+	if r.opts.InputFormat != InputFormatAuto {
+		return r.opts.InputFormat
+	}
+	if format, ok := extensionFormats[strings.ToLower(filepath.Ext(sourceFileName(source)))]; ok {
+		return format
+	}
+	if mediaType, _, err := mime.ParseMediaType(r.contentTypes[source]); err == nil {
+		if format, ok := contentTypeFormats[mediaType]; ok {
+			return format
+		}
+		// Structured syntax suffixes, e.g. application/vnd.openslo+json.
+		switch {
+		case strings.HasSuffix(mediaType, "+json"):
+			return InputFormatJSON
+		case strings.HasSuffix(mediaType, "+yaml"):
+			return InputFormatYAML
+		}
+	}
+	return sniffInputFormat(data)
+}
+
+// sourceFileName returns the name of the file the source points to,
+// without URL query and fragment, or the name of the file within an archive.
+func sourceFileName(source string) string {
+	if _, name, ok := splitArchivePath(source); ok {
+		return name
+	}
+	if isURL(source) {
+		if u, err := url.Parse(source); err == nil {
+			return u.Path
+		}
+	}
+	return source
+}
+
+// sniffInputFormat detects the format from the content, which must not start with a BOM.
+// Only valid JSON documents are treated as JSON, YAML flow style, like {kind: SLO}, is not.
+func sniffInputFormat(data []byte) InputFormat {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return InputFormatYAML
+	}
+	if json.Valid(trimmed) {
+		return InputFormatJSON
+	}
+	if isNDJSON(trimmed) {
+		return InputFormatNDJSON
+	}
+	return InputFormatYAML
+}
+
+// isNDJSON reports whether every non-empty line is a valid JSON object.
+func isNDJSON(data []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	lines := 0
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if line[0] != '{' || !json.Valid(line) {
+			return false
+		}
+		lines++
+	}
+	return scanner.Err() == nil && lines > 0
+}
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// decodeBOM strips the byte order mark from the content and converts UTF-16 content to UTF-8.
+func decodeBOM(data []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return data[len(bomUTF8):], nil
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return decodeUTF16(data[len(bomUTF16LE):], false)
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return decodeUTF16(data[len(bomUTF16BE):], true)
+	default:
+		return data, nil
+	}
+}
+
+func decodeUTF16(data []byte, bigEndian bool) ([]byte, error) {
+	if len(data)%2 != 0 {
+		return nil, errors.New("invalid UTF-16 content: odd number of bytes")
+	}
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+		} else {
+			units[i] = uint16(data[2*i+1])<<8 | uint16(data[2*i])
+		}
+	}
+	buf := make([]byte, 0, len(data))
+	for _, r := range utf16.Decode(units) {
+		buf = utf8.AppendRune(buf, r)
+	}
+	return buf, nil
+}
+
+// decodeObjects decodes [openslo.Object] from the content in the provided format.
+func decodeObjects(data []byte, format InputFormat) ([]openslo.Object, error) {
+	switch format {
+	case InputFormatJSON:
+		return openslosdk.Decode(bytes.NewReader(data), openslosdk.FormatJSON)
+	case InputFormatNDJSON:
+		return decodeNDJSON(data)
+	default:
+		return openslosdk.Decode(bytes.NewReader(data), openslosdk.FormatYAML)
+	}
+}
+
+// decodeNDJSON decodes a stream of JSON objects, one per line, empty lines are skipped.
+func decodeNDJSON(data []byte) ([]openslo.Object, error) {
+	var objects []openslo.Object
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if line[0] != '{' {
+			return nil, fmt.Errorf("line %d: expected a JSON object", lineNum)
+		}
+		decoded, err := openslosdk.Decode(bytes.NewReader(line), openslosdk.FormatJSON)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		objects = append(objects, decoded...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// ndjsonToYAMLStream converts NDJSON content to a YAML stream, every JSON object is a valid YAML document.
+func ndjsonToYAMLStream(data []byte) []byte {
+	var buf bytes.Buffer
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		buf.WriteString("---\n")
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
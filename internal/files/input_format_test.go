@@ -0,0 +1,125 @@
+package files
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	jsonService   = `{"apiVersion":"openslo/v1","kind":"Service","metadata":{"name":"web"},"spec":{}}`
+	ndjsonService = jsonService + "\n\n" +
+		`{"apiVersion":"openslo/v1","kind":"Service","metadata":{"name":"api"},"spec":{}}` + "\n"
+)
+
+func TestSniffInputFormat(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		data string
+		want InputFormat
+	}{
+		"YAML":                 {data: "apiVersion: openslo/v1\nkind: Service\n", want: InputFormatYAML},
+		"empty":                {data: "", want: InputFormatYAML},
+		"JSON object":          {data: "  \n" + jsonService, want: InputFormatJSON},
+		"JSON list":            {data: "[" + jsonService + "]", want: InputFormatJSON},
+		"YAML flow mapping":    {data: "{apiVersion: openslo/v1, kind: Service}", want: InputFormatYAML},
+		"NDJSON":               {data: ndjsonService, want: InputFormatNDJSON},
+		"YAML flow with lines": {data: "{kind: Service}\n{kind: SLO}\n", want: InputFormatYAML},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, sniffInputFormat([]byte(tc.data)))
+		})
+	}
+}
+
+func TestDecodeBOM(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		data []byte
+		want string
+	}{
+		"no BOM":    {data: []byte(`{"a":"ż"}`), want: `{"a":"ż"}`},
+		"UTF-8":     {data: append([]byte{0xEF, 0xBB, 0xBF}, `{"a":"ż"}`...), want: `{"a":"ż"}`},
+		"UTF-16 LE": {data: []byte{0xFF, 0xFE, '{', 0, '}', 0, 0x7C, 0x01}, want: "{}ż"},
+		"UTF-16 BE": {data: []byte{0xFE, 0xFF, 0, '{', 0, '}', 0x01, 0x7C}, want: "{}ż"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			decoded, err := decodeBOM(tc.data)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, string(decoded))
+		})
+	}
+
+	_, err := decodeBOM([]byte{0xFF, 0xFE, '{'})
+	require.ErrorContains(t, err, "invalid UTF-16 content")
+}
+
+func TestReadObjects_InputFormat(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", r.URL.Query().Get("type"))
+		_, _ = w.Write([]byte(r.URL.Query().Get("body")))
+	}))
+	t.Cleanup(server.Close)
+	sourceURL := func(path, contentType, body string) string {
+		return server.URL + path + "?" + url.Values{"type": {contentType}, "body": {body}}.Encode()
+	}
+
+	tests := map[string]struct {
+		source  string
+		opts    ReadOptions
+		names   []string
+		wantErr string
+	}{
+		"BOM prefixed JSON": {
+			source: sourceURL("/slo", "", "\uFEFF"+jsonService),
+			names:  []string{"web"},
+		},
+		"NDJSON detected from content": {
+			source: sourceURL("/slo", "", ndjsonService),
+			names:  []string{"web", "api"},
+		},
+		"NDJSON detected from Content-Type": {
+			source:  sourceURL("/slo", "application/x-ndjson", jsonService+"\n{}"),
+			wantErr: "line 2",
+		},
+		"extension takes precedence over Content-Type": {
+			source:  sourceURL("/slo.yaml", "application/x-ndjson", "kind: [}"),
+			wantErr: "yaml",
+		},
+		"input format takes precedence over extension": {
+			source: sourceURL("/slo.json", "application/json", ndjsonService),
+			opts:   ReadOptions{InputFormat: InputFormatNDJSON},
+			names:  []string{"web", "api"},
+		},
+		"invalid NDJSON line": {
+			source:  sourceURL("/slo.ndjson", "", jsonService+"\n[]"),
+			wantErr: "line 2: expected a JSON object",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			objects, err := newSourceReader(context.Background(), tc.opts).readObjectsFromSource(tc.source)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			names := make([]string, 0, len(objects))
+			for _, o := range objects {
+				names = append(names, o.GetName())
+			}
+			assert.Equal(t, tc.names, names)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package files
+
+import "github.com/google/go-jsonnet"
+
+// JsonnetOptions defines how Jsonnet sources are evaluated.
+type JsonnetOptions struct {
+	// JPaths lists directories searched for imported files,
+	// after the directory of the importing file.
+	JPaths []string
+	// ExtVars are external variables available through std.extVar as strings.
+	ExtVars map[string]string
+	// ExtCodes are external variables available through std.extVar, their values are Jsonnet code.
+	ExtCodes map[string]string
+}
+
+// evaluateJsonnet evaluates the Jsonnet source into JSON.
+// Imports are resolved relative to the source directory first and then against [JsonnetOptions.JPaths].
+func evaluateJsonnet(source string, content []byte, opts JsonnetOptions) ([]byte, error) {
+	vm := jsonnet.MakeVM()
+	vm.Importer(&sourceImporter{
+		source:       source,
+		contents:     jsonnet.MakeContentsRaw(content),
+		FileImporter: jsonnet.FileImporter{JPaths: opts.JPaths},
+	})
+	for name, value := range opts.ExtVars {
+		vm.ExtVar(name, value)
+	}
+	for name, code := range opts.ExtCodes {
+		vm.ExtCode(name, code)
+	}
+	out, err := vm.EvaluateFile(source)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
+}
+
+// sourceImporter serves the already read content of the evaluated source,
+// which might have been downloaded or preprocessed, and reads imported files from disk.
+type sourceImporter struct {
+	jsonnet.FileImporter
+	source   string
+	contents jsonnet.Contents
+}
+
+func (i *sourceImporter) Import(importedFrom, importedPath string) (jsonnet.Contents, string, error) {
+	if importedFrom == "" && importedPath == i.source {
+		return i.contents, i.source, nil
+	}
+	return i.FileImporter.Import(importedFrom, importedPath)
+}
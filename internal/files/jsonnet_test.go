@@ -0,0 +1,63 @@
+package files_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenSLO/oslo/internal/files"
+)
+
+func TestReadObjects_Jsonnet(t *testing.T) {
+	t.Parallel()
+	opts := files.ReadOptions{Jsonnet: files.JsonnetOptions{
+		JPaths:   []string{"testdata/jsonnet/lib"},
+		ExtVars:  map[string]string{"env": "prod"},
+		ExtCodes: map[string]string{"replicas": "1 + 2"},
+	}}
+
+	tests := map[string]struct {
+		source  string
+		opts    files.ReadOptions
+		names   []string
+		wantErr string
+	}{
+		"imports from jpath and external variables": {
+			source: "testdata/jsonnet/services.jsonnet",
+			opts:   opts,
+			names:  []string{"web-prod", "api-prod"},
+		},
+		"imports relative to the file": {
+			source: "testdata/jsonnet/relative.jsonnet",
+			names:  []string{"relative"},
+		},
+		"missing external variable": {
+			source:  "testdata/jsonnet/services.jsonnet",
+			opts:    files.ReadOptions{Jsonnet: files.JsonnetOptions{JPaths: opts.Jsonnet.JPaths}},
+			wantErr: "Undefined external variable: env",
+		},
+		"missing import": {
+			source:  "testdata/jsonnet/services.jsonnet",
+			wantErr: "testdata/jsonnet/services.jsonnet:1:13-39",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			objects, err := files.ReadObjects(context.Background(), []string{tc.source}, tc.opts)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, "failed to evaluate Jsonnet")
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			names := make([]string, 0, len(objects[tc.source]))
+			for _, o := range objects[tc.source] {
+				names = append(names, o.GetName())
+			}
+			assert.Equal(t, tc.names, names)
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package files
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/OpenSLO/go-sdk/pkg/openslo"
+	"github.com/OpenSLO/go-sdk/pkg/openslosdk"
+)
+
+// KubernetesResourceAnnotation is the annotation which records the Kubernetes resource
+// an object was extracted from, see [ReadOptions.ExtractKubernetes].
+const KubernetesResourceAnnotation = "oslo.openslo.com/kubernetes-resource"
+
+// kubernetesOpenSLOKinds lists kinds of custom resources which are treated as OpenSLO objects
+// wrapped in a Kubernetes resource.
+var kubernetesOpenSLOKinds = []openslo.Kind{
+	openslo.KindSLO,
+	openslo.KindSLI,
+	openslo.KindService,
+	openslo.KindDataSource,
+	openslo.KindAlertPolicy,
+	openslo.KindAlertCondition,
+	openslo.KindAlertNotificationTarget,
+}
+
+// kubernetesDataExtensions lists extensions of ConfigMap keys which are searched for OpenSLO objects.
+var kubernetesDataExtensions = []string{".yaml", ".yml", ".json"}
+
+// kubernetesDocument is a document extracted from a Kubernetes manifest.
+type kubernetesDocument struct {
+	// origin describes the resource the document was extracted from,
+	// it's empty for OpenSLO documents which were not embedded in any resource.
+	origin string
+	data   map[string]any
+}
+
+// kubernetesExtractor finds OpenSLO documents in Kubernetes manifests.
+type kubernetesExtractor struct {
+	// groups lists additional API groups of custom resources which wrap OpenSLO objects.
+	groups    []string
+	documents []kubernetesDocument
+}
+
+// extractKubernetesObjects decodes [openslo.Object] embedded in a stream of Kubernetes resources.
+// OpenSLO documents are read as they are, and objects are also extracted from:
+//   - lists of resources, like the ones returned by kubectl get -o yaml,
+//   - ConfigMaps, from every key with a YAML or JSON extension, e.g. slo.yaml,
+//   - custom resources of an OpenSLO kind, like SLO, whose API group contains 'openslo'
+//     or is one of the groups, their spec is either a whole OpenSLO object
+//     or the spec of an openslo/v1 object named after the resource.
+//
+// All other documents are skipped.
+// Extracted objects record the resource they come from in the [KubernetesResourceAnnotation].
+func extractKubernetesObjects(data []byte, format InputFormat, groups []string) ([]openslo.Object, error) {
+	if format == InputFormatNDJSON {
+		data = ndjsonToYAMLStream(data)
+	}
+	e := &kubernetesExtractor{groups: groups}
+	if err := e.extractStream(data, ""); err != nil {
+		return nil, err
+	}
+	var objects []openslo.Object
+	for _, doc := range e.documents {
+		decoded, err := doc.decode()
+		if err != nil {
+			if doc.origin == "" {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%s: %w", doc.origin, err)
+		}
+		objects = append(objects, decoded...)
+	}
+	return objects, nil
+}
+
+// extractStream extracts OpenSLO documents from a YAML stream.
+// The origin describes the resource the stream is embedded in, if any.
+func (e *kubernetesExtractor) extractStream(data []byte, origin string) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for i := 0; ; i++ {
+		var doc any
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if origin != "" {
+				return fmt.Errorf("%s: document %d: %w", origin, i, err)
+			}
+			return fmt.Errorf("document %d: %w", i, err)
+		}
+		if err := e.extract(doc, origin); err != nil {
+			return err
+		}
+	}
+}
+
+func (e *kubernetesExtractor) extract(doc any, origin string) error {
+	switch v := doc.(type) {
+	case []any:
+		for _, item := range v {
+			if err := e.extract(item, origin); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		return e.extractResource(v, origin)
+	}
+	return nil
+}
+
+func (e *kubernetesExtractor) extractResource(resource map[string]any, origin string) error {
+	apiVersion, _ := resource["apiVersion"].(string)
+	kind, _ := resource["kind"].(string)
+	switch {
+	case isOpenSLOAPIVersion(apiVersion):
+		e.documents = append(e.documents, kubernetesDocument{origin: origin, data: resource})
+	case strings.HasSuffix(kind, "List"):
+		items, _ := resource["items"].([]any)
+		for _, item := range items {
+			if err := e.extract(item, origin); err != nil {
+				return err
+			}
+		}
+	case apiVersion == "v1" && kind == "ConfigMap":
+		return e.extractConfigMap(resource)
+	case e.isOpenSLOCustomResource(apiVersion, kind):
+		e.extractCustomResource(resource)
+	}
+	return nil
+}
+
+// extractConfigMap extracts OpenSLO documents from ConfigMap keys with a YAML or JSON extension.
+// Since these keys usually hold configuration of other applications, documents without
+// an OpenSLO apiVersion are skipped, but a value which cannot be parsed is an error naming the key.
+func (e *kubernetesExtractor) extractConfigMap(configMap map[string]any) error {
+	data, _ := configMap["data"].(map[string]any)
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		if slices.Contains(kubernetesDataExtensions, strings.ToLower(path.Ext(key))) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		value, ok := data[key].(string)
+		if !ok {
+			continue
+		}
+		origin := fmt.Sprintf("%s, key %s", kubernetesResourceName(configMap), key)
+		extracted := &kubernetesExtractor{groups: e.groups}
+		if err := extracted.extractStream([]byte(value), origin); err != nil {
+			return err
+		}
+		e.documents = append(e.documents, extracted.documents...)
+	}
+	return nil
+}
+
+// extractCustomResource extracts the OpenSLO object wrapped by the custom resource.
+func (e *kubernetesExtractor) extractCustomResource(resource map[string]any) {
+	spec, ok := resource["spec"].(map[string]any)
+	if !ok {
+		return
+	}
+	origin := kubernetesResourceName(resource)
+	if apiVersion, _ := spec["apiVersion"].(string); isOpenSLOAPIVersion(apiVersion) {
+		e.documents = append(e.documents, kubernetesDocument{origin: origin, data: spec})
+		return
+	}
+	metadata, _ := resource["metadata"].(map[string]any)
+	e.documents = append(e.documents, kubernetesDocument{
+		origin: origin,
+		data: map[string]any{
+			"apiVersion": openslo.VersionV1.String(),
+			"kind":       resource["kind"],
+			"metadata":   map[string]any{"name": metadata["name"]},
+			"spec":       spec,
+		},
+	})
+}
+
+func (e *kubernetesExtractor) isOpenSLOCustomResource(apiVersion, kind string) bool {
+	group, _, ok := strings.Cut(apiVersion, "/")
+	if !ok || !slices.Contains(kubernetesOpenSLOKinds, openslo.Kind(kind)) {
+		return false
+	}
+	return strings.Contains(group, "openslo") || slices.Contains(e.groups, group)
+}
+
+// decode decodes the document, recording its origin in the annotations.
+// Since openslo/v1alpha objects have no annotations, their origin is not recorded.
+func (d kubernetesDocument) decode() ([]openslo.Object, error) {
+	if d.origin != "" && d.data["apiVersion"] != openslo.VersionV1alpha.String() {
+		metadata, _ := d.data["metadata"].(map[string]any)
+		if metadata == nil {
+			metadata = make(map[string]any)
+			d.data["metadata"] = metadata
+		}
+		annotations, _ := metadata["annotations"].(map[string]any)
+		if annotations == nil {
+			annotations = make(map[string]any)
+			metadata["annotations"] = annotations
+		}
+		annotations[KubernetesResourceAnnotation] = d.origin
+	}
+	data, err := json.Marshal(d.data)
+	if err != nil {
+		return nil, err
+	}
+	return openslosdk.Decode(bytes.NewReader(data), openslosdk.FormatJSON)
+}
+
+// KubernetesResource returns the Kubernetes resource the object was extracted from,
+// as recorded in the [KubernetesResourceAnnotation], or an empty string.
+func KubernetesResource(object openslo.Object) string {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return ""
+	}
+	var generic struct {
+		Metadata struct {
+			Annotations map[string]string `json:"annotations"`
+		} `json:"metadata"`
+	}
+	if err = json.Unmarshal(data, &generic); err != nil {
+		return ""
+	}
+	return generic.Metadata.Annotations[KubernetesResourceAnnotation]
+}
+
+// kubernetesResourceName returns the kind, namespace and name of the resource, e.g. ConfigMap monitoring/slos.
+func kubernetesResourceName(resource map[string]any) string {
+	kind, _ := resource["kind"].(string)
+	metadata, _ := resource["metadata"].(map[string]any)
+	name, _ := metadata["name"].(string)
+	if namespace, _ := metadata["namespace"].(string); namespace != "" {
+		name = namespace + "/" + name
+	}
+	return kind + " " + name
+}
+
+func isOpenSLOAPIVersion(apiVersion string) bool {
+	return strings.HasPrefix(apiVersion, "openslo/") || strings.HasPrefix(apiVersion, "openslo.com/")
+}
@@ -0,0 +1,94 @@
+package files_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenSLO/oslo/internal/files"
+)
+
+func TestReadObjects_ExtractKubernetes(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		source    string
+		groups    []string
+		objects   []string
+		resources []string
+		wantErr   string
+	}{
+		"configmap and custom resource": {
+			source:  "testdata/kubernetes/manifests.yaml",
+			objects: []string{"Service web", "DataSource prometheus", "SLO web-availability"},
+			resources: []string{
+				"ConfigMap monitoring/slos, key slo.yaml",
+				"ConfigMap monitoring/slos, key slo.yaml",
+				"SLO monitoring/web-availability",
+			},
+		},
+		"custom resource group": {
+			source: "testdata/kubernetes/manifests.yaml",
+			groups: []string{"monitoring.example.com"},
+			objects: []string{
+				"Service web",
+				"DataSource prometheus",
+				"SLO web-availability",
+				"SLI web-availability",
+			},
+			resources: []string{
+				"ConfigMap monitoring/slos, key slo.yaml",
+				"ConfigMap monitoring/slos, key slo.yaml",
+				"SLO monitoring/web-availability",
+				"SLI monitoring/web-availability",
+			},
+		},
+		"list": {
+			source:    "testdata/kubernetes/list.yaml",
+			objects:   []string{"Service api", "Service web"},
+			resources: []string{"ConfigMap slos, key slo.json", ""},
+		},
+		"decode error": {
+			source: "testdata/kubernetes/invalid.yaml",
+			wantErr: "ConfigMap monitoring/slos, key slo.yaml: " +
+				`failed to decode openslo/v1 Service: json: unknown field "unknownField"`,
+		},
+		"malformed configmap key": {
+			source:  "testdata/kubernetes/malformed.yaml",
+			wantErr: "ConfigMap monitoring/slos, key slo.yaml: document 0: yaml: line 4:",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			objects, err := files.ReadObjects(context.Background(), []string{tc.source}, files.ReadOptions{
+				ExtractKubernetes: true,
+				KubernetesGroups:  tc.groups,
+			})
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			var names, resources []string
+			for _, o := range objects[tc.source] {
+				require.NoError(t, o.Validate())
+				names = append(names, o.GetKind().String()+" "+o.GetName())
+				resources = append(resources, files.KubernetesResource(o))
+			}
+			assert.Equal(t, tc.objects, names)
+			assert.Equal(t, tc.resources, resources)
+		})
+	}
+}
+
+func TestReadObjects_KubernetesManifestsWithoutExtraction(t *testing.T) {
+	t.Parallel()
+	_, err := files.ReadObjects(
+		context.Background(),
+		[]string{"testdata/kubernetes/manifests.yaml"},
+		files.ReadOptions{},
+	)
+	require.Error(t, err)
+}
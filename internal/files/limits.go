@@ -0,0 +1,89 @@
+package files
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// sizeLimitError is returned when the content exceeds the configured size limit.
+type sizeLimitError struct {
+	source string
+	limit  int64
+}
+
+func (e sizeLimitError) Error() string {
+	return fmt.Sprintf("%s exceeds the maximum size of %d bytes", e.source, e.limit)
+}
+
+// readLimited reads the whole content of the reader,
+// failing with [sizeLimitError] if it's larger than the limit.
+// Non-positive limit means there's no limit.
+func readLimited(r io.Reader, limit int64, source string) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, sizeLimitError{source: source, limit: limit}
+	}
+	return data, nil
+}
+
+// hostNotAllowedError is returned when a source is hosted on a host which is not allowed to be accessed.
+type hostNotAllowedError struct {
+	source string
+	host   string
+}
+
+func (e hostNotAllowedError) Error() string {
+	return fmt.Sprintf("host %s of %s is not allowed", e.host, e.source)
+}
+
+// checkHost verifies the host against [ReadOptions.AllowedHosts] and [ReadOptions.DeniedHosts].
+// Denied hosts take precedence over allowed ones.
+func (o ReadOptions) checkHost(source, host string) error {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if matchAnyHost(o.DeniedHosts, host) || (len(o.AllowedHosts) > 0 && !matchAnyHost(o.AllowedHosts, host)) {
+		return hostNotAllowedError{source: source, host: host}
+	}
+	return nil
+}
+
+// checkURLHost verifies the host of the URL, see [ReadOptions.checkHost].
+func (o ReadOptions) checkURLHost(rawURL string) error {
+	if len(o.AllowedHosts) == 0 && len(o.DeniedHosts) == 0 {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	return o.checkHost(rawURL, u.Hostname())
+}
+
+// matchAnyHost reports whether the host matches any of the patterns.
+// A pattern is either a host name, an IP address, or a wildcard like *.example.com matching all subdomains.
+func matchAnyHost(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSuffix(pattern, "."))
+		if suffix, ok := strings.CutPrefix(pattern, "*"); ok && strings.HasPrefix(suffix, ".") {
+			if strings.HasSuffix(host, suffix) {
+				return true
+			}
+			continue
+		}
+		if h, _, err := net.SplitHostPort(pattern); err == nil {
+			pattern = h
+		}
+		if pattern == host {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package files
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchAnyHost(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		patterns []string
+		host     string
+		want     bool
+	}{
+		"exact":                     {[]string{"example.com"}, "example.com", true},
+		"case insensitive":          {[]string{"Example.COM"}, "example.com", true},
+		"different host":            {[]string{"example.com"}, "example.org", false},
+		"wildcard subdomain":        {[]string{"*.example.com"}, "raw.example.com", true},
+		"wildcard nested subdomain": {[]string{"*.example.com"}, "a.b.example.com", true},
+		"wildcard does not match apex": {
+			[]string{"*.example.com"}, "example.com", false,
+		},
+		"wildcard suffix only":    {[]string{"*.example.com"}, "badexample.com", false},
+		"port in pattern ignored": {[]string{"localhost:8080"}, "localhost", true},
+		"IP address":              {[]string{"127.0.0.1"}, "127.0.0.1", true},
+		"no patterns":             {nil, "example.com", false},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, matchAnyHost(tc.patterns, tc.host))
+		})
+	}
+}
+
+func TestReadConf_Limits(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "http://example.com/slo.yaml", http.StatusFound)
+			return
+		}
+		// Unknown length, the body is streamed.
+		w.(http.Flusher).Flush()
+		_, _ = w.Write([]byte(testInput))
+	}))
+	t.Cleanup(server.Close)
+	read := func(opts ReadOptions, path string) ([]byte, error) {
+		return newSourceReader(context.Background(), opts).readRawSchema(path)
+	}
+
+	t.Run("denied host", func(t *testing.T) {
+		t.Parallel()
+		_, err := read(ReadOptions{DeniedHosts: []string{"127.0.0.1"}}, server.URL)
+		require.ErrorContains(t, err, "host 127.0.0.1 of "+server.URL+" is not allowed")
+	})
+
+	t.Run("host not in allowlist", func(t *testing.T) {
+		t.Parallel()
+		_, err := read(ReadOptions{AllowedHosts: []string{"*.example.com"}}, server.URL)
+		require.ErrorContains(t, err, "host 127.0.0.1 of "+server.URL+" is not allowed")
+	})
+
+	t.Run("redirect to host not in allowlist", func(t *testing.T) {
+		t.Parallel()
+		opts := ReadOptions{AllowedHosts: []string{"127.0.0.1"}, HTTP: HTTPOptions{Retries: 3}}
+		_, err := read(opts, server.URL+"/redirect")
+		require.ErrorContains(t, err, "host example.com of http://example.com/slo.yaml is not allowed")
+	})
+
+	t.Run("allowed host", func(t *testing.T) {
+		t.Parallel()
+		content, err := read(ReadOptions{AllowedHosts: []string{"127.0.0.1"}}, server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(testInput), content)
+	})
+
+	t.Run("response too large", func(t *testing.T) {
+		t.Parallel()
+		opts := ReadOptions{HTTP: HTTPOptions{MaxResponseSize: 10, Retries: 3}}
+		_, err := read(opts, server.URL+"/large")
+		require.ErrorContains(t, err, server.URL+"/large exceeds the maximum size of 10 bytes")
+	})
+
+	t.Run("local file too large", func(t *testing.T) {
+		t.Parallel()
+		_, err := read(ReadOptions{MaxFileSize: 10}, "./test-input")
+		require.ErrorContains(t, err, "./test-input exceeds the maximum size of 10 bytes")
+	})
+
+	t.Run("archived file too large", func(t *testing.T) {
+		t.Parallel()
+		// Highly compressible content, the archive itself is within the limit.
+		path := filepath.Join(t.TempDir(), "slos.tgz")
+		f, err := os.Create(path)
+		require.NoError(t, err)
+		content := strings.Repeat("a", 10_000)
+		gz := gzip.NewWriter(f)
+		tw := tar.NewWriter(gz)
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: "slo.yaml", Mode: 0o600, Size: int64(len(content))}))
+		_, err = tw.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, tw.Close())
+		require.NoError(t, gz.Close())
+		require.NoError(t, f.Close())
+		_, err = read(ReadOptions{MaxFileSize: 1000}, path+archiveSeparator+"slo.yaml")
+		require.ErrorContains(t, err, path+archiveSeparator+"slo.yaml exceeds the maximum size of 1000 bytes")
+	})
+
+	t.Run("archive too large", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "slos.tgz")
+		require.NoError(t, os.WriteFile(path, createTestTarGz(t, map[string]int{"a.yaml": 600, "b.yaml": 600}), 0o600))
+		_, err := read(ReadOptions{MaxArchiveSize: 1000}, path+archiveSeparator+"a.yaml")
+		require.ErrorContains(t, err, "content of archive "+path+" exceeds the maximum size of 1000 bytes")
+	})
+
+	t.Run("archive with too many entries", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "slos.tgz")
+		entries := make(map[string]int, maxArchiveEntries+1)
+		for i := range maxArchiveEntries + 1 {
+			entries[fmt.Sprintf("%d.yaml", i)] = 0
+		}
+		require.NoError(t, os.WriteFile(path, createTestTarGz(t, entries), 0o600))
+		_, err := read(ReadOptions{}, path+archiveSeparator+"0.yaml")
+		require.ErrorContains(t, err, fmt.Sprintf("archive has more than %d entries", maxArchiveEntries))
+	})
+
+	t.Run("file within limit", func(t *testing.T) {
+		t.Parallel()
+		content, err := read(ReadOptions{MaxFileSize: int64(len(testInput))}, "./test-input")
+		require.NoError(t, err)
+		assert.Equal(t, []byte(testInput), content)
+	})
+}
+
+// createTestTarGz creates a gzip compressed tar archive with files of the given sizes.
+func createTestTarGz(t *testing.T, sizes map[string]int) []byte {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for name, size := range sizes {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(size)}))
+		_, err := tw.Write(bytes.Repeat([]byte("a"), size))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
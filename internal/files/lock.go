@@ -0,0 +1,173 @@
+package files
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+)
+
+// LockFileName is the default name of the lock file.
+const LockFileName = "oslo.lock"
+
+const lockFileHeader = "# This file is generated by 'oslo lock update', do not edit it manually.\n"
+
+// Lock records the digests of remote sources, URLs and git repositories,
+// so that their content can be verified on subsequent reads.
+// It's safe for concurrent use.
+type Lock struct {
+	mu      sync.Mutex
+	sources map[string]LockedSource
+}
+
+// LockedSource is a single remote source recorded in the [Lock].
+type LockedSource struct {
+	// Source is the URL or the git source, exactly as it was provided.
+	Source string `json:"source"`
+	// URL is the final URL of a URL source, after following redirects.
+	URL string `json:"url,omitempty"`
+	// Commit is the hash of the commit the git ref was resolved to.
+	Commit string `json:"commit,omitempty"`
+	// SHA256 is the hex encoded digest of the downloaded content.
+	// For git sources it's computed from the paths and digests of all files in the requested directory.
+	SHA256 string `json:"sha256"`
+}
+
+type lockFile struct {
+	Sources []LockedSource `json:"sources"`
+}
+
+// NewLock returns an empty [Lock].
+func NewLock() *Lock {
+	return &Lock{sources: make(map[string]LockedSource)}
+}
+
+// LoadLock reads the [Lock] from the file.
+func LoadLock(path string) (*Lock, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock file: %w", err)
+	}
+	var file lockFile
+	if err = yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode lock file %s: %w", path, err)
+	}
+	lock := NewLock()
+	for _, src := range file.Sources {
+		lock.sources[src.Source] = src
+	}
+	return lock, nil
+}
+
+// Save writes the [Lock] to the file, sources are sorted to keep the file stable.
+func (l *Lock) Save(path string) error {
+	file := lockFile{Sources: l.Sources()}
+	if file.Sources == nil {
+		file.Sources = []LockedSource{}
+	}
+	data, err := yaml.Marshal(file)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append([]byte(lockFileHeader), data...), 0o600)
+}
+
+// Sources returns all sources recorded in the [Lock], sorted by their names.
+func (l *Lock) Sources() []LockedSource {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.SortedFunc(maps.Values(l.sources), func(a, b LockedSource) int {
+		return strings.Compare(a.Source, b.Source)
+	})
+}
+
+func (l *Lock) get(source string) (LockedSource, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	src, ok := l.sources[source]
+	return src, ok
+}
+
+func (l *Lock) record(src LockedSource) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sources[src.Source] = src
+}
+
+// lockedCommit returns the commit recorded for the git source, or an empty string
+// if the source should be resolved from its ref.
+func (o ReadOptions) lockedCommit(source string) (string, error) {
+	if o.Lock == nil || !o.Locked {
+		return "", nil
+	}
+	src, ok := o.Lock.get(source)
+	if !ok || src.Commit == "" {
+		return "", fmt.Errorf("git source %s is not recorded in the lock file", source)
+	}
+	return src.Commit, nil
+}
+
+// checkLock records the digest of the remote source in the [Lock], along with the URL or commit it resolved to,
+// or verifies them against the recorded ones in locked mode.
+func (o ReadOptions) checkLock(src LockedSource) error {
+	if o.Lock == nil {
+		return nil
+	}
+	if !o.Locked {
+		o.Lock.record(src)
+		return nil
+	}
+	locked, ok := o.Lock.get(src.Source)
+	if !ok {
+		return fmt.Errorf("source %s is not recorded in the lock file", src.Source)
+	}
+	if locked.URL != "" && locked.URL != src.URL {
+		return fmt.Errorf("resolved URL mismatch for %s: lock file has %s, got %s", src.Source, locked.URL, src.URL)
+	}
+	if locked.Commit != src.Commit {
+		return fmt.Errorf("commit mismatch for %s: lock file has %s, got %s", src.Source, locked.Commit, src.Commit)
+	}
+	if locked.SHA256 != src.SHA256 {
+		return fmt.Errorf("checksum mismatch for %s: lock file has sha256 %s, got %s",
+			src.Source, locked.SHA256, src.SHA256)
+	}
+	return nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hashDir computes a digest of all regular files in the directory, including their relative paths.
+func hashDir(dir string) (string, error) {
+	var buf bytes.Buffer
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		data, err := os.ReadFile(path) // #nosec G304
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&buf, "%s  %s\n", sha256Hex(data), filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return sha256Hex(buf.Bytes()), nil
+}
@@ -0,0 +1,64 @@
+package files_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenSLO/oslo/internal/files"
+)
+
+func TestLock(t *testing.T) {
+	t.Parallel()
+	var content, target atomic.Value
+	content.Store(archivedService)
+	target.Store("/v1/service.yaml")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/latest/service.yaml" {
+			http.Redirect(w, r, target.Load().(string), http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte(content.Load().(string)))
+	}))
+	defer server.Close()
+	url := server.URL + "/latest/service.yaml"
+	lockPath := filepath.Join(t.TempDir(), files.LockFileName)
+
+	read := func(opts files.ReadOptions) error {
+		_, err := files.ReadObjects(context.Background(), []string{url}, opts)
+		return err
+	}
+
+	lock := files.NewLock()
+	require.NoError(t, read(files.ReadOptions{Lock: lock}))
+	require.NoError(t, lock.Save(lockPath))
+	assert.Equal(t, []files.LockedSource{{
+		Source: url,
+		URL:    server.URL + "/v1/service.yaml",
+		SHA256: "95aa7f4a334cfaef443f3a2fb9456d044e6feb862c7d68d59b498c0621d2e04f",
+	}}, lock.Sources())
+
+	loaded, err := files.LoadLock(lockPath)
+	require.NoError(t, err)
+	assert.Equal(t, lock.Sources(), loaded.Sources())
+	require.NoError(t, read(files.ReadOptions{Lock: loaded, Locked: true}))
+
+	target.Store("/v2/service.yaml")
+	err = read(files.ReadOptions{Lock: loaded, Locked: true})
+	require.ErrorContains(t, err, "resolved URL mismatch for "+url+": lock file has "+server.URL+"/v1/service.yaml, "+
+		"got "+server.URL+"/v2/service.yaml")
+
+	target.Store("/v1/service.yaml")
+	content.Store(archivedService + "  description: changed\n")
+	err = read(files.ReadOptions{Lock: loaded, Locked: true})
+	require.ErrorContains(t, err, "checksum mismatch for "+url+": lock file has sha256 95aa7f4a")
+
+	err = read(files.ReadOptions{Lock: files.NewLock(), Locked: true})
+	require.ErrorContains(t, err, "source "+url+" is not recorded in the lock file")
+}
@@ -1,25 +1,71 @@
 package files
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 
 	"github.com/OpenSLO/go-sdk/pkg/openslo"
-	"github.com/OpenSLO/go-sdk/pkg/openslosdk"
+
+	"github.com/OpenSLO/oslo/internal/envexpand"
+	"github.com/OpenSLO/oslo/internal/render"
 )
 
+// ReadOptions defines how sources are read.
+type ReadOptions struct {
+	// HTTP defines how sources are downloaded from URLs.
+	HTTP HTTPOptions
+	// Offline disables network access, URL and git sources are served only from the oslo cache directory.
+	Offline bool
+	// Lock, if set, records the digest of every remote source.
+	Lock *Lock
+	// Session, if set, is shared by all reads of a single command, see [Session].
+	Session *Session
+	// Locked makes reading fail if a remote source is missing from the Lock or its digest differs.
+	// Git sources are checked out at the commits recorded in the Lock.
+	Locked bool
+	// AllowedHosts, if not empty, lists the only hosts URL and git sources can be fetched from.
+	// Wildcards like *.example.com match all subdomains.
+	AllowedHosts []string
+	// DeniedHosts lists hosts URL and git sources must not be fetched from, it takes precedence over AllowedHosts.
+	DeniedHosts []string
+	// InputFormat overrides the detected format of every source.
+	InputFormat InputFormat
+	// Jsonnet defines how Jsonnet sources are evaluated.
+	Jsonnet JsonnetOptions
+	// Render enables rendering sources as Go templates with the Values, see [render.Render].
+	Render bool
+	// Values are passed to the rendered templates.
+	Values map[string]any
+	// ExpandEnv enables substitution of environment variable references, like ${VAR}, before decoding.
+	// See [envexpand.Expand] for the supported syntax.
+	ExpandEnv bool
+	// ExpandEnvStrict makes the substitution fail if a referenced variable is not set.
+	ExpandEnvStrict bool
+	// MaxFileSize is the maximum size in bytes of a local file, a file read from standard input
+	// or a file extracted from an archive. Zero means no limit.
+	MaxFileSize int64
+	// MaxArchiveSize is the maximum total size in bytes of all files extracted from a single archive.
+	// Zero means [DefaultMaxArchiveSize].
+	MaxArchiveSize int64
+	// ExtractKubernetes enables extraction of objects embedded in Kubernetes resources,
+	// other Kubernetes resources are skipped, see [KubernetesResourceAnnotation].
+	ExtractKubernetes bool
+	// KubernetesGroups lists API groups of custom resources which wrap OpenSLO objects,
+	// in addition to the groups which contain 'openslo'.
+	KubernetesGroups []string
+}
+
 // ReadObjects reads [openslo.Object] from the provided sources.
 // It returns a map where the key is a file path and the value are objects read form this file.
-func ReadObjects(sources []string) (map[string][]openslo.Object, error) {
+func ReadObjects(ctx context.Context, sources []string, opts ReadOptions) (map[string][]openslo.Object, error) {
+	r := newSourceReader(ctx, opts)
 	allObjects := make(map[string][]openslo.Object)
 	for _, src := range sources {
-		objects, err := readObjectsFromSource(src)
+		objects, err := r.readObjectsFromSource(src)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read objects from %s: %w", src, err)
 		}
@@ -28,42 +74,151 @@ func ReadObjects(sources []string) (map[string][]openslo.Object, error) {
 	return allObjects, nil
 }
 
-func readObjectsFromSource(source string) ([]openslo.Object, error) {
-	data, err := readRawSchema(source)
+// sourceReader reads raw content of the sources.
+type sourceReader struct {
+	ctx  context.Context
+	opts ReadOptions
+
+	client    *http.Client
+	clientErr error
+	// contentTypes holds Content-Type headers of downloaded sources.
+	contentTypes map[string]string
+	// resolvedURLs holds the final URLs of downloaded sources, after following redirects.
+	resolvedURLs map[string]string
+	// session holds archives which were already read and records sources which were decrypted with SOPS.
+	session *Session
+}
+
+func newSourceReader(ctx context.Context, opts ReadOptions) *sourceReader {
+	session := opts.Session
+	if session == nil {
+		session = NewSession()
+	}
+	return &sourceReader{
+		ctx:          ctx,
+		opts:         opts,
+		contentTypes: make(map[string]string),
+		resolvedURLs: make(map[string]string),
+		session:      session,
+	}
+}
+
+// httpClient returns the HTTP client, it's created on first use,
+// so that no certificates are loaded unless sources are downloaded.
+func (r *sourceReader) httpClient() (*http.Client, error) {
+	if r.client == nil && r.clientErr == nil {
+		r.client, r.clientErr = newHTTPClient(r.opts.HTTP)
+		if r.client != nil {
+			r.client.CheckRedirect = r.checkRedirect
+		}
+	}
+	return r.client, r.clientErr
+}
+
+// checkRedirect verifies that redirects don't lead to hosts which are not allowed.
+func (r *sourceReader) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxHTTPRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxHTTPRedirects)
+	}
+	return r.opts.checkURLHost(req.URL.String())
+}
+
+func (r *sourceReader) readObjectsFromSource(source string) ([]openslo.Object, error) {
+	data, format, err := r.readContent(source)
 	if err != nil {
 		return nil, err
 	}
-	return readObjectsFromRawData(data)
+	if r.opts.ExtractKubernetes {
+		return extractKubernetesObjects(data, format, r.opts.KubernetesGroups)
+	}
+	return decodeObjects(data, format)
 }
 
-// readObjectsFromRawData reads [openslo.Object] from a byte slice.
-func readObjectsFromRawData(data []byte) ([]openslo.Object, error) {
-	format := openslosdk.FormatYAML
-	if isJSONBuffer(data) {
-		format = openslosdk.FormatJSON
+// readContent reads the raw content of the source, strips its byte order mark and detects its format.
+// SOPS encrypted YAML and JSON content is decrypted.
+// If enabled, the content is rendered as a template and environment variables are expanded, in that order.
+// Jsonnet and CUE sources are evaluated, so the returned content is always YAML, JSON or NDJSON.
+func (r *sourceReader) readContent(source string) ([]byte, InputFormat, error) {
+	data, err := r.readRawSchema(source)
+	if err != nil {
+		return nil, "", err
+	}
+	if data, err = decodeBOM(data); err != nil {
+		return nil, "", err
 	}
-	return openslosdk.Decode(bytes.NewReader(data), format)
+	if format := r.detectInputFormat(source, data); format == InputFormatYAML || format == InputFormatJSON {
+		if isSOPSEncrypted(data) {
+			if data, err = decryptSOPS(data, format); err != nil {
+				return nil, "", fmt.Errorf("failed to decrypt SOPS encrypted content: %w", err)
+			}
+			r.session.setDecrypted(source)
+		}
+	}
+	if r.opts.Render {
+		if data, err = render.Render(source, data, r.opts.Values); err != nil {
+			return nil, "", fmt.Errorf("failed to render template: %w", err)
+		}
+	}
+	if r.opts.ExpandEnv {
+		data, err = envexpand.Expand(data, os.LookupEnv, envexpand.Options{Strict: r.opts.ExpandEnvStrict})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to expand environment variables: %w", err)
+		}
+	}
+	format := r.detectInputFormat(source, data)
+	if format == InputFormatJsonnet {
+		if data, err = evaluateJsonnet(source, data, r.opts.Jsonnet); err != nil {
+			return nil, "", fmt.Errorf("failed to evaluate Jsonnet: %w", err)
+		}
+		format = InputFormatJSON
+	}
+	if format == InputFormatCUE {
+		if data, err = evaluateCUE(source, data); err != nil {
+			return nil, "", fmt.Errorf("failed to evaluate CUE: %w", err)
+		}
+		format = InputFormatJSON
+	}
+	return data, format, nil
 }
 
 // readRawSchema reads raw OpenSLO schema from file path, HTTP address or stdin (path "-") to a byte slice.
-func readRawSchema(path string) ([]byte, error) {
+// Files within archives are read from the archive, see [Discover] for details.
+func (r *sourceReader) readRawSchema(path string) ([]byte, error) {
+	if archivePath, name, ok := splitArchivePath(path); ok {
+		return r.readArchiveFile(archivePath, name)
+	}
 	switch {
 	case isStdin(path):
-		return io.ReadAll(os.Stdin)
+		return readLimited(os.Stdin, r.opts.MaxFileSize, "standard input")
 	case isURL(path):
-		// nolint: gosec,noctx
-		// #nosec G107
-		resp, err := http.Get(path)
+		data, err := r.fetchURL(path)
 		if err != nil {
 			return nil, err
 		}
-		defer func() { _ = resp.Body.Close() }()
-		return io.ReadAll(resp.Body)
+		locked := LockedSource{Source: path, URL: r.resolvedURLs[path], SHA256: sha256Hex(data)}
+		if err = r.opts.checkLock(locked); err != nil {
+			return nil, err
+		}
+		return data, nil
 	default:
-		return os.ReadFile(filepath.Clean(path))
+		return r.readLocalFile(path)
 	}
 }
 
+func (r *sourceReader) readLocalFile(path string) ([]byte, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	if r.opts.MaxFileSize > 0 {
+		if info, err := f.Stat(); err == nil && info.Mode().IsRegular() && info.Size() > r.opts.MaxFileSize {
+			return nil, sizeLimitError{source: path, limit: r.opts.MaxFileSize}
+		}
+	}
+	return readLimited(f, r.opts.MaxFileSize, path)
+}
+
 func isStdin(p string) bool {
 	return p == "-"
 }
@@ -72,11 +227,15 @@ func isURL(p string) bool {
 	return strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "https://")
 }
 
-var jsonBufferRegex = regexp.MustCompile(`^\s*\[?\s*{`)
-
-// isJSONBuffer scans the provided buffer, looking for an open brace indicating this is JSON.
-// While a simple list like ["a", "b", "c"] is still a valid JSON,
-// it does not really concern us when processing complex objects.
-func isJSONBuffer(buf []byte) bool {
-	return jsonBufferRegex.Match(buf)
+// realPath returns the absolute path of p with all symbolic links resolved.
+// If the links cannot be resolved, e.g. because the file does not exist, the absolute path is returned.
+func realPath(p string) (string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved, nil
+	}
+	return abs, nil
 }
@@ -1,12 +1,22 @@
 package files
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	_ "embed"
+	"encoding/pem"
 	"net/http"
 	"net/http/httptest"
+	neturl "net/url"
 	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	v1 "github.com/OpenSLO/go-sdk/pkg/openslo/v1"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -18,7 +28,7 @@ func TestReadConf(t *testing.T) {
 
 	t.Run("from filepath successfully", func(t *testing.T) {
 		const filePath = "./test-input"
-		content, err := readRawSchema(filePath)
+		content, err := newSourceReader(context.Background(), ReadOptions{}).readRawSchema(filePath)
 		require.NoErrorf(t, err, "can't read content from filepath %q", filePath)
 		require.Equal(t, expectedContent, content)
 	})
@@ -30,7 +40,7 @@ func TestReadConf(t *testing.T) {
 		}))
 		defer server.Close()
 
-		content, err := readRawSchema(server.URL)
+		content, err := newSourceReader(context.Background(), ReadOptions{}).readRawSchema(server.URL)
 		require.NoErrorf(t, err, "can't read content from URL of the test server: %q", server.URL)
 		require.Equal(t, expectedContent, content)
 	})
@@ -47,8 +57,370 @@ func TestReadConf(t *testing.T) {
 		os.Stdin = output
 
 		const indicateStdin = "-"
-		content, err := readRawSchema(indicateStdin)
+		content, err := newSourceReader(context.Background(), ReadOptions{}).readRawSchema(indicateStdin)
 		require.NoError(t, err, "can't read content from stdin")
 		require.Equal(t, expectedContent, content)
 	})
 }
+
+func TestReadConf_HTTP(t *testing.T) {
+	t.Parallel()
+	opts := ReadOptions{HTTP: HTTPOptions{
+		Retries:      2,
+		RetryBackoff: time.Millisecond,
+		Headers:      http.Header{"X-Team": []string{"platform"}},
+		BearerToken:  "secret",
+	}}
+
+	t.Run("sends headers and bearer token", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "platform", r.Header.Get("X-Team"))
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			_, _ = w.Write([]byte(testInput))
+		}))
+		defer server.Close()
+
+		content, err := newSourceReader(context.Background(), opts).readRawSchema(server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(testInput), content)
+	})
+
+	t.Run("non-2xx status is an error", func(t *testing.T) {
+		t.Parallel()
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			http.NotFound(w, r)
+		}))
+		defer server.Close()
+
+		_, err := newSourceReader(context.Background(), opts).readRawSchema(server.URL)
+		require.ErrorContains(t, err, "unexpected response status from "+server.URL+": 404 Not Found")
+		assert.Equal(t, int32(1), requests.Load(), "4xx responses must not be retried")
+	})
+
+	t.Run("5xx status is retried", func(t *testing.T) {
+		t.Parallel()
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			_, _ = w.Write([]byte(testInput))
+		}))
+		defer server.Close()
+
+		content, err := newSourceReader(context.Background(), opts).readRawSchema(server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(testInput), content)
+		assert.Equal(t, int32(3), requests.Load())
+	})
+
+	t.Run("retries are limited", func(t *testing.T) {
+		t.Parallel()
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		_, err := newSourceReader(context.Background(), opts).readRawSchema(server.URL)
+		require.ErrorContains(t, err, "503 Service Unavailable")
+		assert.Equal(t, int32(3), requests.Load())
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		timeoutOpts := ReadOptions{HTTP: HTTPOptions{Timeout: 10 * time.Millisecond}}
+		_, err := newSourceReader(context.Background(), timeoutOpts).readRawSchema(server.URL)
+		require.ErrorContains(t, err, "Client.Timeout exceeded")
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := newSourceReader(ctx, opts).readRawSchema(server.URL)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestReadConf_TLS(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	newServer := func(t *testing.T, clientAuth tls.ClientAuthType) *httptest.Server {
+		t.Helper()
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if clientAuth != tls.NoClientCert && len(r.TLS.PeerCertificates) == 0 {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(testInput))
+		}))
+		server.TLS = &tls.Config{ClientAuth: clientAuth, MinVersion: tls.VersionTLS12}
+		server.StartTLS()
+		t.Cleanup(server.Close)
+		return server
+	}
+	writePEM := func(t *testing.T, name, blockType string, data []byte) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data}), 0o600))
+		return path
+	}
+
+	t.Run("unknown authority is an error", func(t *testing.T) {
+		t.Parallel()
+		server := newServer(t, tls.NoClientCert)
+		_, err := newSourceReader(context.Background(), ReadOptions{}).readRawSchema(server.URL)
+		require.ErrorContains(t, err, "certificate signed by unknown authority")
+	})
+
+	t.Run("custom CA bundle", func(t *testing.T) {
+		t.Parallel()
+		server := newServer(t, tls.NoClientCert)
+		caFile := writePEM(t, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+		opts := ReadOptions{HTTP: HTTPOptions{CAFile: caFile}}
+		content, err := newSourceReader(context.Background(), opts).readRawSchema(server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(testInput), content)
+	})
+
+	t.Run("invalid CA bundle", func(t *testing.T) {
+		t.Parallel()
+		server := newServer(t, tls.NoClientCert)
+		caFile := filepath.Join(dir, "invalid-ca.pem")
+		require.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0o600))
+		opts := ReadOptions{HTTP: HTTPOptions{CAFile: caFile}}
+		_, err := newSourceReader(context.Background(), opts).readRawSchema(server.URL)
+		require.ErrorContains(t, err, "no PEM encoded certificates found in CA file")
+	})
+
+	t.Run("client certificate", func(t *testing.T) {
+		t.Parallel()
+		server := newServer(t, tls.RequireAnyClientCert)
+		cert := server.TLS.Certificates[0]
+		key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+		require.NoError(t, err)
+		opts := ReadOptions{HTTP: HTTPOptions{
+			CAFile:   writePEM(t, "mtls-ca.pem", "CERTIFICATE", server.Certificate().Raw),
+			CertFile: writePEM(t, "client.pem", "CERTIFICATE", cert.Certificate[0]),
+			KeyFile:  writePEM(t, "client-key.pem", "PRIVATE KEY", key),
+		}}
+		content, err := newSourceReader(context.Background(), opts).readRawSchema(server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(testInput), content)
+	})
+
+	t.Run("client certificate without key", func(t *testing.T) {
+		t.Parallel()
+		server := newServer(t, tls.NoClientCert)
+		opts := ReadOptions{HTTP: HTTPOptions{CertFile: filepath.Join(dir, "client.pem")}}
+		_, err := newSourceReader(context.Background(), opts).readRawSchema(server.URL)
+		require.ErrorContains(t, err, "both client certificate and key files must be provided")
+	})
+
+	t.Run("skip verification", func(t *testing.T) {
+		t.Parallel()
+		server := newServer(t, tls.NoClientCert)
+		opts := ReadOptions{HTTP: HTTPOptions{InsecureSkipVerify: true}}
+		content, err := newSourceReader(context.Background(), opts).readRawSchema(server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(testInput), content)
+	})
+
+	t.Run("proxy", func(t *testing.T) {
+		t.Parallel()
+		var proxied atomic.Bool
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied.Store(true)
+			assert.Equal(t, "http://example.com/slo.yaml", r.URL.String())
+			_, _ = w.Write([]byte(testInput))
+		}))
+		defer proxy.Close()
+		opts := ReadOptions{HTTP: HTTPOptions{Proxy: proxy.URL}}
+		content, err := newSourceReader(context.Background(), opts).readRawSchema("http://example.com/slo.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, []byte(testInput), content)
+		assert.True(t, proxied.Load())
+	})
+}
+
+func TestReadConf_HTTPCache(t *testing.T) {
+	t.Setenv(cacheDirEnv, t.TempDir())
+
+	const etag = `"v1"`
+	var requests, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("If-None-Match") == etag {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(testInput))
+	}))
+	defer server.Close()
+
+	read := func(opts ReadOptions, url string) ([]byte, error) {
+		return newSourceReader(context.Background(), opts).readRawSchema(url)
+	}
+
+	t.Run("offline without cached entry", func(t *testing.T) {
+		_, err := read(ReadOptions{Offline: true}, server.URL)
+		require.ErrorIs(t, err, errNotCached)
+		assert.Equal(t, int32(0), requests.Load())
+	})
+
+	t.Run("cache is revalidated", func(t *testing.T) {
+		opts := ReadOptions{HTTP: HTTPOptions{Cache: true}}
+		for range 2 {
+			content, err := read(opts, server.URL)
+			require.NoError(t, err)
+			assert.Equal(t, []byte(testInput), content)
+		}
+		assert.Equal(t, int32(2), requests.Load())
+		assert.Equal(t, int32(1), notModified.Load())
+	})
+
+	t.Run("offline with cached entry", func(t *testing.T) {
+		server.Close()
+		content, err := read(ReadOptions{Offline: true}, server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(testInput), content)
+	})
+}
+
+func TestReadConf_HTTPCacheAuthenticated(t *testing.T) {
+	t.Setenv(cacheDirEnv, t.TempDir())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cacheControl := r.URL.Query().Get("cache-control"); cacheControl != "" {
+			w.Header().Set("Cache-Control", cacheControl)
+		}
+		_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	read := func(opts ReadOptions, url string) ([]byte, error) {
+		return newSourceReader(context.Background(), opts).readRawSchema(url)
+	}
+	withToken := func(token string, offline bool) ReadOptions {
+		return ReadOptions{Offline: offline, HTTP: HTTPOptions{Cache: true, CacheAuthenticated: true, BearerToken: token}}
+	}
+
+	t.Run("authenticated requests are not cached by default", func(t *testing.T) {
+		url := server.URL + "/default"
+		_, err := read(ReadOptions{HTTP: HTTPOptions{Cache: true, BearerToken: "a"}}, url)
+		require.NoError(t, err)
+		_, err = read(withToken("a", true), url)
+		require.ErrorIs(t, err, errNotCached)
+	})
+
+	t.Run("responses are cached separately for every credential", func(t *testing.T) {
+		url := server.URL + "/opt-in"
+		content, err := read(withToken("a", false), url)
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer a", string(content))
+
+		content, err = read(withToken("a", true), url)
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer a", string(content))
+		_, err = read(withToken("b", true), url)
+		require.ErrorIs(t, err, errNotCached)
+		_, err = read(ReadOptions{Offline: true}, url)
+		require.ErrorIs(t, err, errNotCached)
+		headerOpts := withToken("a", true)
+		headerOpts.HTTP.Headers = http.Header{"X-Api-Key": {"secret"}}
+		_, err = read(headerOpts, url)
+		require.ErrorIs(t, err, errNotCached)
+	})
+
+	t.Run("Cache-Control", func(t *testing.T) {
+		for cacheControl, cached := range map[string]bool{
+			"no-store":              false,
+			"private, max-age=60":   false,
+			`private="Set-Cookie"`:  false,
+			"public, max-age=60":    true,
+			"no-cache, max-age=600": true,
+		} {
+			url := server.URL + "/?cache-control=" + neturl.QueryEscape(cacheControl)
+			_, err := read(ReadOptions{HTTP: HTTPOptions{Cache: true}}, url)
+			require.NoError(t, err)
+			_, err = read(ReadOptions{Offline: true}, url)
+			if cached {
+				assert.NoError(t, err, cacheControl)
+			} else {
+				assert.ErrorIs(t, err, errNotCached, cacheControl)
+			}
+		}
+	})
+}
+
+func TestReadObjects_ExpandEnv(t *testing.T) {
+	t.Setenv("OSLO_TEST_ENV", "prod")
+	path := filepath.Join(t.TempDir(), "service.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`apiVersion: openslo/v1
+kind: Service
+metadata:
+  name: web-${OSLO_TEST_ENV}
+  labels:
+    cluster: ${OSLO_TEST_CLUSTER:-eu-1}
+spec:
+  description: costs $$5 in ${OSLO_TEST_REGION}
+`), 0o600))
+
+	objects, err := ReadObjects(context.Background(), []string{path}, ReadOptions{ExpandEnv: true})
+	require.NoError(t, err)
+	require.Len(t, objects[path], 1)
+	service, ok := objects[path][0].(v1.Service)
+	require.True(t, ok)
+	assert.Equal(t, "web-prod", service.Metadata.Name)
+	assert.Equal(t, v1.Labels{"cluster": {"eu-1"}}, service.Metadata.Labels)
+	assert.Equal(t, "costs $5 in", service.Spec.Description)
+
+	_, err = ReadObjects(context.Background(), []string{path}, ReadOptions{ExpandEnv: true, ExpandEnvStrict: true})
+	require.ErrorContains(t, err, "failed to expand environment variables: line 8: variable OSLO_TEST_REGION is not set")
+
+	objects, err = ReadObjects(context.Background(), []string{path}, ReadOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "web-${OSLO_TEST_ENV}", objects[path][0].GetName(), "expansion must be opt-in")
+}
+
+func TestReadObjects_Render(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "service.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`apiVersion: openslo/v1
+kind: Service
+metadata:
+  name: web-{{ .Values.env }}
+spec:
+  description: {{ required "description is required" .Values.description | quote }}
+`), 0o600))
+
+	opts := ReadOptions{Render: true, Values: map[string]any{"env": "prod", "description": "Web: frontend"}}
+	objects, err := ReadObjects(context.Background(), []string{path}, opts)
+	require.NoError(t, err)
+	require.Len(t, objects[path], 1)
+	assert.Equal(t, "web-prod", objects[path][0].GetName())
+
+	opts.Values = map[string]any{"env": "prod"}
+	_, err = ReadObjects(context.Background(), []string{path}, opts)
+	require.ErrorContains(t, err, "failed to render template: template: "+path+":6:18:")
+	require.ErrorContains(t, err, "description is required")
+}
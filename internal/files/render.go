@@ -0,0 +1,34 @@
+package files
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// Render renders the sources as Go templates with [ReadOptions.Values]
+// and writes the results to the provided writer, separated with YAML document separators.
+// The rendered content is not decoded, so that it can be inspected even if it's not valid.
+func Render(ctx context.Context, out io.Writer, sources []string, opts ReadOptions) error {
+	opts.Render = true
+	r := newSourceReader(ctx, opts)
+	for i, src := range sources {
+		content, _, err := r.readContent(src)
+		if err != nil {
+			return fmt.Errorf("failed to render %s: %w", src, err)
+		}
+		if i > 0 {
+			if _, err = fmt.Fprintln(out, "---"); err != nil {
+				return err
+			}
+		}
+		if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+			content = append(content, '\n')
+		}
+		if _, err = out.Write(content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package files
+
+import (
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Session holds the state shared by all reads of a single command,
+// set the same Session in [ReadOptions] passed to [Discover], [ReadObjects] and other functions reading sources.
+// It keeps archives which were already read, so that each of them is only downloaded and extracted once,
+// and records which sources were decrypted with SOPS, see [Session.Decrypted].
+// It's safe for concurrent use.
+type Session struct {
+	mu        sync.Mutex
+	decrypted map[string]bool
+	archives  map[string]*archive
+}
+
+// NewSession returns an empty [Session].
+func NewSession() *Session {
+	return &Session{
+		decrypted: make(map[string]bool),
+		archives:  make(map[string]*archive),
+	}
+}
+
+// Decrypted returns sorted sources which were decrypted with SOPS.
+// Their content must not be written in plaintext unless the user explicitly allows it, see [ErrDecryptedOutput].
+func (s *Session) Decrypted() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Sorted(maps.Keys(s.decrypted))
+}
+
+func (s *Session) setDecrypted(source string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.decrypted[source] = true
+}
+
+func (s *Session) isDecrypted(source string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.decrypted[source]
+}
+
+func (s *Session) archive(path string) (*archive, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.archives[path]
+	return a, ok
+}
+
+func (s *Session) setArchive(path string, a *archive) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.archives[path] = a
+}
@@ -0,0 +1,516 @@
+package files
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getsops/sops/v3/age"
+	"github.com/getsops/sops/v3/pgp"
+	"github.com/getsops/sops/v3/shamir"
+	"go.yaml.in/yaml/v3"
+)
+
+// sopsMetadataKey is the top-level key under which SOPS stores its metadata.
+const sopsMetadataKey = "sops"
+
+// sopsDefaultUnencryptedSuffix is used by SOPS when no other encryption rule is set.
+const sopsDefaultUnencryptedSuffix = "_unencrypted"
+
+// sopsMACOnlyEncryptedInitialization prefixes the MAC of files encrypted with mac_only_encrypted,
+// it's copied from SOPS.
+var sopsMACOnlyEncryptedInitialization = []byte{
+	0x8a, 0x3f, 0xd2, 0xad, 0x54, 0xce, 0x66, 0x52, 0x7b, 0x10, 0x34, 0xf3, 0xd1, 0x47, 0xbe, 0xb,
+	0xb, 0x97, 0x5b, 0x3b, 0xf4, 0x4f, 0x72, 0xc6, 0xfd, 0xad, 0xec, 0x81, 0x76, 0xf2, 0x7d, 0x69,
+}
+
+var sopsEncryptedValueRegexp = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.+),iv:(.+),tag:(.+),type:(.+)\]`)
+
+// isSOPSEncrypted reports whether the YAML or JSON content was encrypted with SOPS,
+// that is, whether any of its documents holds SOPS metadata with a message authentication code.
+func isSOPSEncrypted(data []byte) bool {
+	if !bytes.Contains(data, []byte(sopsMetadataKey)) {
+		return false
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc struct {
+			SOPS struct {
+				MAC string `yaml:"mac"`
+			} `yaml:"sops"`
+		}
+		// Content which is not valid YAML is left for the decoder to report.
+		if err := dec.Decode(&doc); err != nil {
+			return false
+		}
+		if doc.SOPS.MAC != "" {
+			return true
+		}
+	}
+}
+
+// sopsMetadata holds the parts of SOPS metadata needed to decrypt content.
+type sopsMetadata struct {
+	sopsKeyGroup            `yaml:",inline"`
+	KeyGroups               []sopsKeyGroup `yaml:"key_groups"`
+	ShamirThreshold         int            `yaml:"shamir_threshold"`
+	LastModified            string         `yaml:"lastmodified"`
+	MAC                     string         `yaml:"mac"`
+	UnencryptedSuffix       string         `yaml:"unencrypted_suffix"`
+	EncryptedSuffix         string         `yaml:"encrypted_suffix"`
+	UnencryptedRegex        string         `yaml:"unencrypted_regex"`
+	EncryptedRegex          string         `yaml:"encrypted_regex"`
+	UnencryptedCommentRegex string         `yaml:"unencrypted_comment_regex"`
+	EncryptedCommentRegex   string         `yaml:"encrypted_comment_regex"`
+	MACOnlyEncrypted        bool           `yaml:"mac_only_encrypted"`
+}
+
+// sopsKeyGroup lists the master keys which encrypt the same data key, or its shamir share.
+// Only age and PGP keys are used, the others are only listed to report them.
+type sopsKeyGroup struct {
+	Age []struct {
+		Recipient        string `yaml:"recipient"`
+		EncryptedDataKey string `yaml:"enc"`
+	} `yaml:"age"`
+	PGP []struct {
+		Fingerprint      string `yaml:"fp"`
+		EncryptedDataKey string `yaml:"enc"`
+	} `yaml:"pgp"`
+	KMS           []any `yaml:"kms"`
+	GCPKMS        []any `yaml:"gcp_kms"`
+	AzureKeyVault []any `yaml:"azure_kv"`
+	Vault         []any `yaml:"hc_vault"`
+}
+
+// decryptSOPS decrypts the YAML or JSON content encrypted with SOPS in memory.
+// Only locally available keys are used, age identities are read the same way the sops binary does,
+// for instance from SOPS_AGE_KEY_FILE or SOPS_AGE_KEY, and PGP keys from the local GnuPG keyring.
+// Content which can only be decrypted with cloud KMS or Vault keys is rejected,
+// so that no remote service is ever contacted.
+func decryptSOPS(data []byte, format InputFormat) ([]byte, error) {
+	var documents []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var document yaml.Node
+		if err := dec.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		documents = append(documents, &document)
+	}
+	if len(documents) == 0 {
+		return nil, errors.New("SOPS metadata not found")
+	}
+	var holder struct {
+		Metadata *sopsMetadata `yaml:"sops"`
+	}
+	if err := documents[0].Decode(&holder); err != nil {
+		return nil, fmt.Errorf("invalid SOPS metadata: %w", err)
+	}
+	if holder.Metadata == nil {
+		return nil, errors.New("SOPS metadata not found")
+	}
+	d, err := newSOPSDecrypter(*holder.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	for _, document := range documents {
+		removeMappingKey(document, sopsMetadataKey)
+		if err = d.walk(document, nil); err != nil {
+			return nil, err
+		}
+	}
+	if err = d.verifyMAC(); err != nil {
+		return nil, err
+	}
+	return encodeDecryptedDocuments(documents, format)
+}
+
+// sopsDecrypter decrypts the values of SOPS encrypted documents
+// and computes their message authentication code.
+type sopsDecrypter struct {
+	metadata          sopsMetadata
+	dataKey           []byte
+	mac               hash.Hash
+	unencryptedRegexp *regexp.Regexp
+	encryptedRegexp   *regexp.Regexp
+}
+
+func newSOPSDecrypter(metadata sopsMetadata) (*sopsDecrypter, error) {
+	if metadata.UnencryptedCommentRegex != "" || metadata.EncryptedCommentRegex != "" {
+		return nil, errors.New("SOPS encrypted_comment_regex and unencrypted_comment_regex are not supported")
+	}
+	rules := 0
+	for _, rule := range []string{
+		metadata.UnencryptedSuffix, metadata.EncryptedSuffix,
+		metadata.UnencryptedRegex, metadata.EncryptedRegex,
+	} {
+		if rule != "" {
+			rules++
+		}
+	}
+	switch {
+	case rules > 1:
+		return nil, errors.New("SOPS metadata must not define more than one encryption rule")
+	case rules == 0:
+		metadata.UnencryptedSuffix = sopsDefaultUnencryptedSuffix
+	}
+	d := &sopsDecrypter{metadata: metadata, mac: sha512.New()}
+	var err error
+	if metadata.UnencryptedRegex != "" {
+		if d.unencryptedRegexp, err = regexp.Compile(metadata.UnencryptedRegex); err != nil {
+			return nil, fmt.Errorf("invalid SOPS unencrypted_regex: %w", err)
+		}
+	}
+	if metadata.EncryptedRegex != "" {
+		if d.encryptedRegexp, err = regexp.Compile(metadata.EncryptedRegex); err != nil {
+			return nil, fmt.Errorf("invalid SOPS encrypted_regex: %w", err)
+		}
+	}
+	if metadata.MACOnlyEncrypted {
+		d.mac.Write(sopsMACOnlyEncryptedInitialization)
+	}
+	if d.dataKey, err = metadata.dataKey(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// dataKey decrypts the data key with any of the age or PGP keys of every key group,
+// if there are multiple key groups, the data key is combined from their shamir shares.
+func (m sopsMetadata) dataKey() ([]byte, error) {
+	groups := m.KeyGroups
+	if len(groups) == 0 {
+		groups = []sopsKeyGroup{m.sopsKeyGroup}
+	}
+	var (
+		parts [][]byte
+		errs  []error
+	)
+	for i, group := range groups {
+		part, err := group.decryptDataKey()
+		if err != nil {
+			if len(groups) > 1 {
+				err = fmt.Errorf("key group %d: %w", i, err)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		parts = append(parts, part)
+	}
+	if len(groups) == 1 {
+		if len(parts) == 0 {
+			return nil, errors.Join(errs...)
+		}
+		return parts[0], nil
+	}
+	threshold := m.ShamirThreshold
+	if threshold == 0 {
+		threshold = len(groups)
+	}
+	if len(parts) < threshold {
+		return nil, fmt.Errorf("decrypted %d of %d required key groups: %w", len(parts), threshold, errors.Join(errs...))
+	}
+	dataKey, err := shamir.Combine(parts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to combine data key from key groups: %w", err)
+	}
+	return dataKey, nil
+}
+
+func (g sopsKeyGroup) decryptDataKey() ([]byte, error) {
+	unsupported := g.unsupportedKeyTypes()
+	if len(g.Age) == 0 && len(g.PGP) == 0 {
+		return nil, fmt.Errorf("only age and PGP keys are supported, but the data key is only encrypted with %s keys",
+			strings.Join(unsupported, ", "))
+	}
+	var errs []error
+	for _, ageKey := range g.Age {
+		key, err := age.MasterKeyFromRecipient(ageKey.Recipient)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		key.SetEncryptedDataKey([]byte(ageKey.EncryptedDataKey))
+		dataKey, err := key.Decrypt()
+		if err == nil {
+			return dataKey, nil
+		}
+		errs = append(errs, err)
+	}
+	for _, pgpKey := range g.PGP {
+		key := pgp.NewMasterKeyFromFingerprint(pgpKey.Fingerprint)
+		key.SetEncryptedDataKey([]byte(pgpKey.EncryptedDataKey))
+		dataKey, err := key.Decrypt()
+		if err == nil {
+			return dataKey, nil
+		}
+		errs = append(errs, err)
+	}
+	if len(unsupported) > 0 {
+		errs = append(errs, fmt.Errorf("%s keys were not tried, only age and PGP keys are supported",
+			strings.Join(unsupported, ", ")))
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (g sopsKeyGroup) unsupportedKeyTypes() []string {
+	var types []string
+	for _, keys := range []struct {
+		name  string
+		count int
+	}{
+		{"AWS KMS", len(g.KMS)},
+		{"GCP KMS", len(g.GCPKMS)},
+		{"Azure Key Vault", len(g.AzureKeyVault)},
+		{"HashiCorp Vault", len(g.Vault)},
+	} {
+		if keys.count > 0 {
+			types = append(types, keys.name)
+		}
+	}
+	return types
+}
+
+// walk decrypts all values of the node in place, path holds the mapping keys leading to the node.
+// Comments are removed, since SOPS encrypts them as well.
+func (d *sopsDecrypter) walk(node *yaml.Node, path []string) error {
+	node.HeadComment, node.LineComment, node.FootComment = "", "", ""
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			if err := d.walk(child, path); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			key.HeadComment, key.LineComment, key.FootComment = "", "", ""
+			if err := d.walk(node.Content[i+1], append(path, key.Value)); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		return d.decryptScalar(node, path)
+	case yaml.AliasNode:
+		return errors.New("YAML aliases are not supported in SOPS encrypted content")
+	}
+	return nil
+}
+
+func (d *sopsDecrypter) decryptScalar(node *yaml.Node, path []string) error {
+	if node.ShortTag() == "!!null" {
+		return nil
+	}
+	encrypted := d.shouldBeEncrypted(path)
+	var macData []byte
+	if encrypted {
+		plaintext, dataType, err := d.decryptValue(node.Value, strings.Join(path, ":")+":")
+		if err != nil {
+			return fmt.Errorf("failed to decrypt value at '%s': %w", strings.Join(path, "."), err)
+		}
+		if macData, err = setDecryptedScalar(node, plaintext, dataType); err != nil {
+			return fmt.Errorf("invalid value at '%s': %w", strings.Join(path, "."), err)
+		}
+	} else {
+		var value any
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		var err error
+		if macData, err = sopsMACBytes(value); err != nil {
+			return fmt.Errorf("invalid value at '%s': %w", strings.Join(path, "."), err)
+		}
+	}
+	if !d.metadata.MACOnlyEncrypted || encrypted {
+		d.mac.Write(macData)
+	}
+	return nil
+}
+
+// shouldBeEncrypted mirrors the SOPS rules deciding which values are encrypted.
+func (d *sopsDecrypter) shouldBeEncrypted(path []string) bool {
+	matchesAny := func(match func(string) bool) bool {
+		for _, key := range path {
+			if match(key) {
+				return true
+			}
+		}
+		return false
+	}
+	switch m := d.metadata; {
+	case m.UnencryptedSuffix != "":
+		return !matchesAny(func(key string) bool { return strings.HasSuffix(key, m.UnencryptedSuffix) })
+	case m.EncryptedSuffix != "":
+		return matchesAny(func(key string) bool { return strings.HasSuffix(key, m.EncryptedSuffix) })
+	case d.unencryptedRegexp != nil:
+		return !matchesAny(d.unencryptedRegexp.MatchString)
+	case d.encryptedRegexp != nil:
+		return matchesAny(d.encryptedRegexp.MatchString)
+	default:
+		return true
+	}
+}
+
+// decryptValue decrypts a single ENC[AES256_GCM,...] value and returns its plaintext and SOPS data type.
+func (d *sopsDecrypter) decryptValue(value, additionalData string) (plaintext []byte, dataType string, err error) {
+	if value == "" {
+		return nil, "str", nil
+	}
+	matches := sopsEncryptedValueRegexp.FindStringSubmatch(value)
+	if matches == nil {
+		return nil, "", errors.New("value does not match the SOPS format")
+	}
+	parts := make([][]byte, 3)
+	for i := range parts {
+		if parts[i], err = base64.StdEncoding.DecodeString(matches[i+1]); err != nil {
+			return nil, "", fmt.Errorf("invalid base64 encoding: %w", err)
+		}
+	}
+	data, iv, tag := parts[0], parts[1], parts[2]
+	block, err := aes.NewCipher(d.dataKey)
+	if err != nil {
+		return nil, "", err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return nil, "", err
+	}
+	plaintext, err = gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decrypt with AES GCM: %w", err)
+	}
+	return plaintext, matches[4], nil
+}
+
+func (d *sopsDecrypter) verifyMAC() error {
+	lastModified, err := time.Parse(time.RFC3339, d.metadata.LastModified)
+	if err != nil {
+		return fmt.Errorf("invalid SOPS lastmodified: %w", err)
+	}
+	fileMAC, _, err := d.decryptValue(d.metadata.MAC, lastModified.Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt SOPS MAC: %w", err)
+	}
+	if computedMAC := fmt.Sprintf("%X", d.mac.Sum(nil)); string(fileMAC) != computedMAC {
+		return errors.New("SOPS MAC mismatch, the content was modified after it was encrypted")
+	}
+	return nil
+}
+
+// setDecryptedScalar replaces the node's value with the plaintext of the given SOPS data type
+// and returns the bytes SOPS adds to the MAC for it.
+func setDecryptedScalar(node *yaml.Node, plaintext []byte, dataType string) ([]byte, error) {
+	text := string(plaintext)
+	node.Style = 0
+	switch dataType {
+	case "str", "bytes":
+		node.Tag, node.Value = "!!str", text
+		return plaintext, nil
+	case "int":
+		v, err := strconv.Atoi(text)
+		if err != nil {
+			return nil, err
+		}
+		node.Tag, node.Value = "!!int", text
+		return sopsMACBytes(v)
+	case "float":
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, err
+		}
+		node.Tag, node.Value = "!!float", text
+		return sopsMACBytes(v)
+	case "bool":
+		v, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, err
+		}
+		node.Tag, node.Value = "!!bool", strconv.FormatBool(v)
+		return sopsMACBytes(v)
+	case "time":
+		var v time.Time
+		if err := v.UnmarshalText(plaintext); err != nil {
+			return nil, err
+		}
+		node.Tag, node.Value = "!!timestamp", text
+		return sopsMACBytes(v)
+	default:
+		return nil, fmt.Errorf("unknown SOPS data type: %s", dataType)
+	}
+}
+
+// sopsMACBytes returns the representation of the value SOPS adds to the MAC.
+func sopsMACBytes(value any) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		return []byte(v), nil
+	case int:
+		return []byte(strconv.Itoa(v)), nil
+	case int64:
+		return []byte(strconv.FormatInt(v, 10)), nil
+	case uint64:
+		return []byte(strconv.FormatUint(v, 10)), nil
+	case float64:
+		return []byte(strconv.FormatFloat(v, 'f', -1, 64)), nil
+	case bool:
+		if v {
+			return []byte("True"), nil
+		}
+		return []byte("False"), nil
+	case time.Time:
+		return v.MarshalText()
+	default:
+		return nil, fmt.Errorf("unsupported type %T", value)
+	}
+}
+
+// removeMappingKey removes the key from the document's top-level mapping.
+func removeMappingKey(document *yaml.Node, key string) {
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return
+	}
+	mapping := document.Content[0]
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+func encodeDecryptedDocuments(documents []*yaml.Node, format InputFormat) ([]byte, error) {
+	if format == InputFormatJSON {
+		var value any
+		if err := documents[0].Decode(&value); err != nil {
+			return nil, err
+		}
+		return json.Marshal(value)
+	}
+	buf := new(bytes.Buffer)
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	for _, document := range documents {
+		if err := enc.Encode(document); err != nil {
+			return nil, err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,102 @@
+package files_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OpenSLO/go-sdk/pkg/openslo"
+	"github.com/OpenSLO/go-sdk/pkg/openslosdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/OpenSLO/go-sdk/pkg/openslo/v1"
+
+	"github.com/OpenSLO/oslo/internal/files"
+)
+
+const sopsAgeKeyFileEnv = "SOPS_AGE_KEY_FILE"
+
+func TestReadObjects_SOPS(t *testing.T) {
+	t.Setenv(sopsAgeKeyFileEnv, filepath.Join("testdata", "sops", "age.key"))
+
+	sources := []string{
+		filepath.Join("testdata", "sops", "datasource.yaml"),
+		filepath.Join("testdata", "sops", "datasource.json"),
+	}
+	session := files.NewSession()
+	objects, err := files.ReadObjects(context.Background(), sources, files.ReadOptions{Session: session})
+	require.NoError(t, err)
+	assert.Equal(t, []string{sources[1], sources[0]}, session.Decrypted())
+
+	require.Len(t, objects[sources[0]], 1)
+	prometheus, ok := objects[sources[0]][0].(v1.DataSource)
+	require.True(t, ok)
+	assert.Equal(t, "prometheus", prometheus.GetName())
+	assert.Equal(t, `{"token":"s3cr3t","url":"https://prometheus.example.com"}`,
+		string(prometheus.Spec.ConnectionDetails))
+
+	require.Len(t, objects[sources[1]], 1)
+	assert.Equal(t, "datadog", objects[sources[1]][0].GetName())
+	assert.Equal(t, openslo.KindDataSource, objects[sources[1]][0].GetKind())
+}
+
+func TestReadObjects_SOPSMissingKey(t *testing.T) {
+	t.Setenv(sopsAgeKeyFileEnv, filepath.Join(t.TempDir(), "missing.key"))
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	_, err := files.ReadObjects(
+		context.Background(),
+		[]string{filepath.Join("testdata", "sops", "datasource.yaml")},
+		files.ReadOptions{},
+	)
+	require.ErrorContains(t, err, "failed to decrypt SOPS encrypted content")
+}
+
+func TestFormat_SOPS(t *testing.T) {
+	t.Setenv(sopsAgeKeyFileEnv, filepath.Join("testdata", "sops", "age.key"))
+	sources := []string{filepath.Join("testdata", "sops", "datasource.yaml")}
+
+	t.Run("refuse decrypted output", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := files.Format(context.Background(), out, openslosdk.FormatYAML, sources, files.FormatOptions{})
+		require.ErrorIs(t, err, files.ErrDecryptedOutput)
+		assert.Empty(t, out.String())
+	})
+	t.Run("allow decrypted output", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		err := files.Format(context.Background(), out, openslosdk.FormatYAML, sources, files.FormatOptions{
+			AllowDecrypted: true,
+		})
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "token: s3cr3t")
+	})
+}
+
+func TestReadObjects_SOPSUnsupportedKeys(t *testing.T) {
+	t.Setenv(sopsAgeKeyFileEnv, filepath.Join("testdata", "sops", "age.key"))
+
+	_, err := files.ReadObjects(
+		context.Background(),
+		[]string{filepath.Join("testdata", "sops", "datasource-kms.yaml")},
+		files.ReadOptions{},
+	)
+	require.ErrorContains(t, err, "failed to decrypt SOPS encrypted content: only age and PGP keys are supported, "+
+		"but the data key is only encrypted with AWS KMS, HashiCorp Vault keys")
+}
+
+func TestReadObjects_SOPSModifiedContent(t *testing.T) {
+	t.Setenv(sopsAgeKeyFileEnv, filepath.Join("testdata", "sops", "age.key"))
+
+	data, err := os.ReadFile(filepath.Join("testdata", "sops", "datasource.yaml"))
+	require.NoError(t, err)
+	modified := filepath.Join(t.TempDir(), "datasource.yaml")
+	data = bytes.Replace(data, []byte("name: prometheus"), []byte("name: modified"), 1)
+	require.NoError(t, os.WriteFile(modified, data, 0o600))
+
+	_, err = files.ReadObjects(context.Background(), []string{modified}, files.ReadOptions{})
+	require.ErrorContains(t, err, "SOPS MAC mismatch, the content was modified after it was encrypted")
+}
@@ -0,0 +1,156 @@
+package files
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/OpenSLO/go-sdk/pkg/openslo"
+	"github.com/OpenSLO/go-sdk/pkg/openslosdk"
+)
+
+// DefaultSplitPathTemplate is the default template used by [Split] to build object file paths.
+// The extension matches [SplitOptions.Format].
+const DefaultSplitPathTemplate = "{{.Kind}}/{{.Name}}{{.Ext}}"
+
+// SplitOptions defines the behavior of [Split].
+type SplitOptions struct {
+	// Dir is the directory in which split files are written.
+	Dir string
+	// PathTemplate is a [text/template] used to build each object's file path relative to Dir.
+	// It's executed with [SplitPathData].
+	PathTemplate string
+	// Format is the format in which objects are written.
+	Format openslosdk.ObjectFormat
+	// Overwrite allows replacing files which already exist.
+	Overwrite bool
+	// DeleteSources removes the local source files once all objects were written.
+	DeleteSources bool
+}
+
+// SplitPathData is the data passed to [SplitOptions.PathTemplate].
+type SplitPathData struct {
+	APIVersion string
+	Kind       string
+	Name       string
+	// Ext is the file extension of [SplitOptions.Format], including the dot, e.g. .yaml.
+	Ext string
+}
+
+// Split writes every object to its own file, using [SplitOptions.PathTemplate] to build its path.
+// All paths are computed before anything is written,
+// if two objects resolve to the same path, an error is returned and no file is created.
+// It returns the paths of written files.
+func Split(objectsPerSource map[string][]openslo.Object, opts SplitOptions) ([]string, error) {
+	tpl, err := template.New("path").
+		Funcs(template.FuncMap{"lower": strings.ToLower}).
+		Option("missingkey=error").
+		Parse(opts.PathTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path template: %w", err)
+	}
+
+	sources := slices.Sorted(maps.Keys(objectsPerSource))
+	pathOwners := make(map[string]string)
+	type splitObject struct {
+		path   string
+		object openslo.Object
+	}
+	var splitObjects []splitObject
+	for _, src := range sources {
+		for _, object := range objectsPerSource[src] {
+			p, err := renderSplitPath(tpl, opts.Dir, object, opts.Format)
+			if err != nil {
+				return nil, fmt.Errorf("failed to build path for %s from %s: %w", object, src, err)
+			}
+			if owner, ok := pathOwners[p]; ok {
+				return nil, fmt.Errorf("name collision: %s and %s from %s both resolve to %s", owner, object, src, p)
+			}
+			pathOwners[p] = fmt.Sprintf("%s from %s", object, src)
+			splitObjects = append(splitObjects, splitObject{path: p, object: object})
+		}
+	}
+	if !opts.Overwrite {
+		for _, so := range splitObjects {
+			if _, err = os.Stat(so.path); err == nil {
+				return nil, fmt.Errorf("file %s already exists", so.path)
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+		}
+	}
+
+	written := make([]string, 0, len(splitObjects))
+	for _, so := range splitObjects {
+		buf := new(bytes.Buffer)
+		if err = encodeObject(buf, opts.Format, so.object); err != nil {
+			return written, err
+		}
+		if err = os.MkdirAll(filepath.Dir(so.path), 0o750); err != nil {
+			return written, err
+		}
+		if err = os.WriteFile(so.path, buf.Bytes(), 0o600); err != nil {
+			return written, err
+		}
+		written = append(written, so.path)
+	}
+
+	if opts.DeleteSources {
+		writtenPaths := make(map[string]bool, len(written))
+		for _, p := range written {
+			if resolved, err := realPath(p); err == nil {
+				writtenPaths[resolved] = true
+			}
+		}
+		for _, src := range sources {
+			if isStdin(src) || isURL(src) {
+				continue
+			}
+			resolved, err := realPath(src)
+			if err != nil {
+				return written, fmt.Errorf("failed to resolve source file path: %w", err)
+			}
+			// Never delete a file which was just written.
+			if writtenPaths[resolved] {
+				continue
+			}
+			if err = os.Remove(src); err != nil {
+				return written, fmt.Errorf("failed to delete source file: %w", err)
+			}
+		}
+	}
+	return written, nil
+}
+
+func renderSplitPath(
+	tpl *template.Template,
+	dir string,
+	object openslo.Object,
+	format openslosdk.ObjectFormat,
+) (string, error) {
+	ext := ".yaml"
+	if format == openslosdk.FormatJSON {
+		ext = ".json"
+	}
+	buf := new(bytes.Buffer)
+	if err := tpl.Execute(buf, SplitPathData{
+		APIVersion: object.GetVersion().String(),
+		Kind:       object.GetKind().String(),
+		Name:       object.GetName(),
+		Ext:        ext,
+	}); err != nil {
+		return "", err
+	}
+	rel := filepath.Clean(buf.String())
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("path %q must be a relative path which does not escape the output directory", buf.String())
+	}
+	return filepath.Join(dir, rel), nil
+}
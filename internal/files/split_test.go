@@ -0,0 +1,152 @@
+package files_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OpenSLO/go-sdk/pkg/openslo"
+	v1 "github.com/OpenSLO/go-sdk/pkg/openslo/v1"
+	"github.com/OpenSLO/go-sdk/pkg/openslosdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenSLO/oslo/internal/files"
+)
+
+func TestSplit(t *testing.T) {
+	t.Parallel()
+
+	service := func(name string) openslo.Object {
+		return v1.NewService(v1.Metadata{Name: name}, v1.ServiceSpec{})
+	}
+
+	t.Run("writes each object to its own file", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		written, err := files.Split(map[string][]openslo.Object{
+			"a.yaml": {service("my-service-1"), service("my-service-2")},
+		}, files.SplitOptions{
+			Dir:          dir,
+			PathTemplate: files.DefaultSplitPathTemplate,
+			Format:       openslosdk.FormatYAML,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			filepath.Join(dir, "Service", "my-service-1.yaml"),
+			filepath.Join(dir, "Service", "my-service-2.yaml"),
+		}, written)
+		data, err := os.ReadFile(written[0])
+		require.NoError(t, err)
+		assert.Equal(t, `apiVersion: openslo/v1
+kind: Service
+metadata:
+  name: my-service-1
+spec: {}
+`, string(data))
+	})
+
+	t.Run("default extension matches the format", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		written, err := files.Split(map[string][]openslo.Object{
+			"a.yaml": {service("my-service")},
+		}, files.SplitOptions{
+			Dir:          dir,
+			PathTemplate: files.DefaultSplitPathTemplate,
+			Format:       openslosdk.FormatJSON,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{filepath.Join(dir, "Service", "my-service.json")}, written)
+	})
+
+	t.Run("name collision", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		_, err := files.Split(map[string][]openslo.Object{
+			"a.yaml": {service("my-service")},
+			"b.yaml": {service("my-service")},
+		}, files.SplitOptions{
+			Dir:          dir,
+			PathTemplate: "{{ lower .Kind }}.yaml",
+			Format:       openslosdk.FormatYAML,
+		})
+		require.EqualError(t, err, fmt.Sprintf(
+			"name collision: v1.Service 'my-service' from a.yaml and v1.Service 'my-service' from b.yaml both resolve to %s",
+			filepath.Join(dir, "service.yaml")))
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("existing file", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "my-service.yaml"), nil, 0o600))
+		opts := files.SplitOptions{
+			Dir:          dir,
+			PathTemplate: "{{ .Name }}.yaml",
+			Format:       openslosdk.FormatYAML,
+		}
+		objects := map[string][]openslo.Object{"a.yaml": {service("my-service")}}
+		_, err := files.Split(objects, opts)
+		require.ErrorContains(t, err, "already exists")
+		opts.Overwrite = true
+		_, err = files.Split(objects, opts)
+		require.NoError(t, err)
+	})
+
+	t.Run("path escaping output directory", func(t *testing.T) {
+		t.Parallel()
+		_, err := files.Split(map[string][]openslo.Object{
+			"a.yaml": {service("my-service")},
+		}, files.SplitOptions{
+			Dir:          t.TempDir(),
+			PathTemplate: "../{{ .Name }}.yaml",
+			Format:       openslosdk.FormatYAML,
+		})
+		require.ErrorContains(t, err, "must be a relative path")
+	})
+
+	t.Run("delete sources", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		src := filepath.Join(dir, "all.yaml")
+		require.NoError(t, os.WriteFile(src, nil, 0o600))
+		written, err := files.Split(map[string][]openslo.Object{
+			src: {service("my-service")},
+		}, files.SplitOptions{
+			Dir:           dir,
+			PathTemplate:  "{{ .Name }}.json",
+			Format:        openslosdk.FormatJSON,
+			DeleteSources: true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{filepath.Join(dir, "my-service.json")}, written)
+		assert.NoFileExists(t, src)
+	})
+	t.Run("keeps source overwritten with a relative path", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		src := filepath.Join(dir, "Service", "my-service.yaml")
+		require.NoError(t, os.MkdirAll(filepath.Dir(src), 0o750))
+		require.NoError(t, os.WriteFile(src, nil, 0o600))
+		wd, err := os.Getwd()
+		require.NoError(t, err)
+		relSrc, err := filepath.Rel(wd, src)
+		require.NoError(t, err)
+		written, err := files.Split(map[string][]openslo.Object{
+			relSrc: {service("my-service")},
+		}, files.SplitOptions{
+			Dir:           dir,
+			PathTemplate:  files.DefaultSplitPathTemplate,
+			Format:        openslosdk.FormatYAML,
+			Overwrite:     true,
+			DeleteSources: true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{src}, written)
+		assert.FileExists(t, src)
+	})
+}
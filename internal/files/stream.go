@@ -0,0 +1,472 @@
+package files
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/OpenSLO/go-sdk/pkg/openslo"
+	"github.com/OpenSLO/go-sdk/pkg/openslosdk"
+)
+
+// sniffStreamSize is the number of bytes peeked at to detect the format of a streamed source.
+const sniffStreamSize = 4096
+
+// StreamFunc is called by [StreamObjects] with the objects decoded from a single document
+// and the zero-based index of that document within the source.
+type StreamFunc func(index int, objects []openslo.Object) error
+
+// StreamObjects reads [openslo.Object] from the source one document at a time and calls fn for each of them.
+// Local files and standard input are read incrementally, so that at most a single document is held in memory,
+// as long as they're YAML, JSON or NDJSON and neither rendering nor environment variable expansion is enabled.
+// Other sources are read whole and then decoded document by document.
+// Decoding errors report the index of the document they were encountered in.
+func StreamObjects(ctx context.Context, source string, opts ReadOptions, fn StreamFunc) error {
+	r := newSourceReader(ctx, opts)
+	err := r.streamDocuments(source, func(doc streamDocument) error {
+		objects, err := r.decodeStreamDocument(source, doc)
+		if err != nil {
+			return err
+		}
+		return fn(doc.index, objects)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read objects from %s: %w", source, err)
+	}
+	return nil
+}
+
+// streamDocument is a single document of a streamed source.
+type streamDocument struct {
+	// index is the zero-based index of the document within the source,
+	// documents holding only white space and comments are not counted.
+	index int
+	// line is the line the document starts at, it's zero if unknown.
+	line   int
+	data   []byte
+	format InputFormat
+}
+
+// wrapError adds the position of the document to the error.
+func (d streamDocument) wrapError(err error) error {
+	if d.line > 0 {
+		return fmt.Errorf("document %d at line %d: %w", d.index, d.line, err)
+	}
+	return fmt.Errorf("document %d: %w", d.index, err)
+}
+
+// streamDocuments calls fn for every document of the source, see [StreamObjects] for details.
+func (r *sourceReader) streamDocuments(source string, fn func(streamDocument) error) error {
+	rd, format, closeFunc, err := r.openStream(source)
+	if err != nil {
+		return err
+	}
+	if rd == nil {
+		data, format, err := r.readContent(source)
+		if err != nil {
+			return err
+		}
+		return splitDocuments(bytes.NewReader(data), format, fn)
+	}
+	defer func() { _ = closeFunc() }()
+	return splitDocuments(rd, format, fn)
+}
+
+// openStream opens the source for incremental reading and detects its format.
+// It returns a nil reader if the source has to be read whole.
+func (r *sourceReader) openStream(source string) (*bufio.Reader, InputFormat, func() error, error) {
+	if r.opts.Render || r.opts.ExpandEnv || isURL(source) {
+		return nil, "", nil, nil
+	}
+	if _, _, ok := splitArchivePath(source); ok {
+		return nil, "", nil, nil
+	}
+	format := r.opts.InputFormat
+	if format == InputFormatAuto && !isStdin(source) {
+		format = extensionFormats[strings.ToLower(filepath.Ext(source))]
+	}
+	if format == InputFormatJsonnet || format == InputFormatCUE {
+		return nil, "", nil, nil
+	}
+
+	var (
+		f         io.Reader
+		closeFunc = func() error { return nil }
+		name      = source
+	)
+	if isStdin(source) {
+		f, name = os.Stdin, "standard input"
+	} else {
+		file, err := os.Open(filepath.Clean(source))
+		if err != nil {
+			return nil, "", nil, err
+		}
+		if r.opts.MaxFileSize > 0 {
+			if info, err := file.Stat(); err == nil && info.Mode().IsRegular() && info.Size() > r.opts.MaxFileSize {
+				_ = file.Close()
+				return nil, "", nil, sizeLimitError{source: source, limit: r.opts.MaxFileSize}
+			}
+		}
+		f, closeFunc = file, file.Close
+	}
+	if r.opts.MaxFileSize > 0 {
+		f = &limitedReader{
+			r:         f,
+			remaining: r.opts.MaxFileSize,
+			err:       sizeLimitError{source: name, limit: r.opts.MaxFileSize},
+		}
+	}
+	br := bufio.NewReaderSize(f, sniffStreamSize)
+	head, err := br.Peek(len(bomUTF8))
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		_ = closeFunc()
+		return nil, "", nil, err
+	}
+	switch {
+	case bytes.HasPrefix(head, bomUTF8):
+		_, _ = br.Discard(len(bomUTF8))
+	case bytes.HasPrefix(head, bomUTF16LE), bytes.HasPrefix(head, bomUTF16BE):
+		// UTF-16 content has to be converted as a whole.
+		data, err := io.ReadAll(br)
+		if err == nil {
+			data, err = decodeBOM(data)
+		}
+		if closeErr := closeFunc(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, "", nil, err
+		}
+		br = bufio.NewReader(bytes.NewReader(data))
+		closeFunc = func() error { return nil }
+	}
+	if format == InputFormatAuto {
+		format = sniffStreamFormat(br)
+	}
+	return br, format, closeFunc, nil
+}
+
+// sniffStreamFormat detects the format from the beginning of the content, without consuming it.
+// If the whole content fits in the sniffed bytes, it's detected with [sniffInputFormat].
+// Otherwise content starting with a line which holds a whole JSON object is treated as NDJSON
+// and other content starting with '{' or '[' as JSON.
+func sniffStreamFormat(br *bufio.Reader) InputFormat {
+	head, err := br.Peek(sniffStreamSize)
+	if err != nil {
+		return sniffInputFormat(head)
+	}
+	trimmed := bytes.TrimLeft(head, " \t\r\n")
+	switch {
+	case len(trimmed) == 0:
+		return InputFormatYAML
+	case trimmed[0] == '[':
+		return InputFormatJSON
+	case trimmed[0] == '{':
+		if line, _, ok := bytes.Cut(trimmed, []byte("\n")); ok && json.Valid(bytes.TrimSpace(line)) {
+			return InputFormatNDJSON
+		}
+		return InputFormatJSON
+	default:
+		return InputFormatYAML
+	}
+}
+
+// splitDocuments splits the content into documents and calls fn for each of them.
+// YAML streams are split on document separators, JSON lists into their elements and NDJSON into lines.
+func splitDocuments(rd io.Reader, format InputFormat, fn func(streamDocument) error) error {
+	switch format {
+	case InputFormatJSON:
+		return splitJSONDocuments(rd, fn)
+	case InputFormatNDJSON:
+		return splitNDJSONDocuments(rd, fn)
+	default:
+		return splitYAMLDocuments(rd, fn)
+	}
+}
+
+func splitYAMLDocuments(rd io.Reader, fn func(streamDocument) error) error {
+	br := bufio.NewReader(rd)
+	var (
+		doc       bytes.Buffer
+		index     int
+		line      int
+		startLine = 1
+	)
+	flush := func() error {
+		defer doc.Reset()
+		if isBlankYAMLDocument(doc.Bytes()) {
+			return nil
+		}
+		data := bytes.Clone(doc.Bytes())
+		err := fn(streamDocument{index: index, line: startLine, data: data, format: InputFormatYAML})
+		index++
+		return err
+	}
+	for {
+		text, err := br.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if len(text) > 0 {
+			line++
+			if isYAMLDocumentSeparator(text) {
+				if flushErr := flush(); flushErr != nil {
+					return flushErr
+				}
+				startLine = line + 1
+			} else {
+				doc.Write(text)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return flush()
+		}
+	}
+}
+
+// isBlankYAMLDocument reports whether the document holds nothing but white space and comments.
+func isBlankYAMLDocument(doc []byte) bool {
+	for line := range bytes.Lines(doc) {
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 && line[0] != '#' {
+			return false
+		}
+	}
+	return true
+}
+
+// isYAMLDocumentSeparator reports whether the line marks the start of a new YAML document.
+func isYAMLDocumentSeparator(line []byte) bool {
+	line = bytes.TrimRight(line, " \t\r\n")
+	return bytes.Equal(line, []byte("---")) || bytes.HasPrefix(line, []byte("--- "))
+}
+
+func splitJSONDocuments(rd io.Reader, fn func(streamDocument) error) error {
+	br := bufio.NewReader(rd)
+	dec := json.NewDecoder(br)
+	first, err := peekNonSpace(br)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	index := 0
+	if first == '[' {
+		if _, err = dec.Token(); err != nil {
+			return err
+		}
+		for dec.More() {
+			var raw json.RawMessage
+			if err = dec.Decode(&raw); err != nil {
+				return fmt.Errorf("document %d: %w", index, err)
+			}
+			if err = fn(streamDocument{index: index, data: raw, format: InputFormatJSON}); err != nil {
+				return err
+			}
+			index++
+		}
+		if _, err = dec.Token(); err != nil {
+			return err
+		}
+	}
+	for {
+		var raw json.RawMessage
+		if err = dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("document %d: %w", index, err)
+		}
+		if err = fn(streamDocument{index: index, data: raw, format: InputFormatJSON}); err != nil {
+			return err
+		}
+		index++
+	}
+}
+
+func splitNDJSONDocuments(rd io.Reader, fn func(streamDocument) error) error {
+	br := bufio.NewReader(rd)
+	index := 0
+	for lineNum := 1; ; lineNum++ {
+		text, err := br.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if line := bytes.TrimSpace(text); len(line) > 0 {
+			if line[0] != '{' {
+				return fmt.Errorf("document %d at line %d: expected a JSON object", index, lineNum)
+			}
+			if err := fn(streamDocument{index: index, line: lineNum, data: line, format: InputFormatJSON}); err != nil {
+				return err
+			}
+			index++
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+	}
+}
+
+// peekNonSpace returns the first byte which is not a white space, without consuming it.
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b, br.UnreadByte()
+	}
+}
+
+// decodeStreamDocument decodes objects from the document, decrypting it first if it's encrypted with SOPS.
+func (r *sourceReader) decodeStreamDocument(source string, doc streamDocument) ([]openslo.Object, error) {
+	data, err := r.decryptStreamDocument(source, doc)
+	if err != nil {
+		return nil, err
+	}
+	var objects []openslo.Object
+	if r.opts.ExtractKubernetes {
+		objects, err = extractKubernetesObjects(data, doc.format, r.opts.KubernetesGroups)
+	} else {
+		objects, err = decodeObjects(data, doc.format)
+	}
+	if err != nil {
+		return nil, doc.wrapError(err)
+	}
+	return objects, nil
+}
+
+// decryptStreamDocument decrypts the document if it holds SOPS metadata.
+// Each document of a multi-document stream must be encrypted on its own.
+func (r *sourceReader) decryptStreamDocument(source string, doc streamDocument) ([]byte, error) {
+	if !isSOPSEncrypted(doc.data) {
+		return doc.data, nil
+	}
+	data, err := decryptSOPS(doc.data, doc.format)
+	if err != nil {
+		return nil, doc.wrapError(fmt.Errorf("failed to decrypt SOPS encrypted content: %w", err))
+	}
+	r.session.setDecrypted(source)
+	return data, nil
+}
+
+// limitedReader reads from r until the limit is exceeded, then it fails with err.
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	err       error
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, l.err
+	}
+	// Read one byte past the limit to detect that it was exceeded.
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, l.err
+	}
+	return n, err
+}
+
+// formatStream formats the source one document at a time, see [StreamObjects] for details.
+// The output is the same as the one produced by [sourceReader.formatFile].
+func (r *sourceReader) formatStream(
+	out io.Writer,
+	format openslosdk.ObjectFormat,
+	source string,
+	opts FormatOptions,
+) error {
+	enc := &streamEncoder{out: out, format: format}
+	err := r.streamDocuments(source, func(doc streamDocument) error {
+		data, err := r.decryptStreamDocument(source, doc)
+		if err != nil {
+			return err
+		}
+		if r.session.isDecrypted(source) && !opts.AllowDecrypted {
+			return fmt.Errorf("%s is encrypted with SOPS: %w", source, ErrDecryptedOutput)
+		}
+		inputFormat := doc.format
+		if opts.Normalize {
+			lineOffset := max(doc.line-1, 0)
+			if data, err = normalizeContent(data, source, opts.Report, lineOffset); err != nil {
+				return doc.wrapError(fmt.Errorf("issue normalizing content: %w", err))
+			}
+			inputFormat = InputFormatYAML
+		}
+		objects, err := decodeObjects(data, inputFormat)
+		if err != nil {
+			return doc.wrapError(fmt.Errorf("issue parsing objects: %w", err))
+		}
+		return enc.encode(objects)
+	})
+	if err != nil {
+		return err
+	}
+	return enc.close()
+}
+
+// streamEncoder writes objects as a single YAML or JSON list, the same way [openslosdk.Encode] does,
+// without holding all of them in memory.
+type streamEncoder struct {
+	out    io.Writer
+	format openslosdk.ObjectFormat
+	count  int
+}
+
+func (e *streamEncoder) encode(objects []openslo.Object) error {
+	if len(objects) == 0 {
+		return nil
+	}
+	if e.format == openslosdk.FormatYAML {
+		e.count += len(objects)
+		// Top-level YAML sequences written one after another form a single sequence.
+		return openslosdk.Encode(e.out, e.format, objects...)
+	}
+	for _, object := range objects {
+		prefix := ",\n  "
+		if e.count == 0 {
+			prefix = "[\n  "
+		}
+		data, err := json.MarshalIndent(object, "  ", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode objects to JSON: %w", err)
+		}
+		if _, err = io.WriteString(e.out, prefix); err != nil {
+			return err
+		}
+		if _, err = e.out.Write(data); err != nil {
+			return err
+		}
+		e.count++
+	}
+	return nil
+}
+
+// close terminates the list, an empty list is written if no objects were encoded.
+func (e *streamEncoder) close() error {
+	if e.count == 0 {
+		return openslosdk.Encode(e.out, e.format, []openslo.Object{}...)
+	}
+	if e.format == openslosdk.FormatJSON {
+		_, err := io.WriteString(e.out, "\n]\n")
+		return err
+	}
+	return nil
+}
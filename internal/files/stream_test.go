@@ -0,0 +1,173 @@
+package files_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OpenSLO/go-sdk/pkg/openslo"
+	"github.com/OpenSLO/go-sdk/pkg/openslosdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenSLO/oslo/internal/files"
+)
+
+func TestStreamObjects(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		name    string
+		content string
+		opts    files.ReadOptions
+		want    map[int][]string
+		wantErr string
+	}{
+		"YAML stream": {
+			name: "services.yaml",
+			content: `---
+apiVersion: openslo/v1
+kind: Service
+metadata:
+  name: web
+spec: {}
+---
+# blank documents are not counted
+---
+- apiVersion: openslo/v1
+  kind: Service
+  metadata:
+    name: api
+  spec: {}
+- apiVersion: openslo/v1
+  kind: Service
+  metadata:
+    name: db
+  spec: {}
+`,
+			want: map[int][]string{0: {"web"}, 1: {"api", "db"}},
+		},
+		"JSON list": {
+			name: "services.json",
+			content: `[
+  {"apiVersion": "openslo/v1", "kind": "Service", "metadata": {"name": "web"}, "spec": {}},
+  {"apiVersion": "openslo/v1", "kind": "Service", "metadata": {"name": "api"}, "spec": {}}
+]`,
+			want: map[int][]string{0: {"web"}, 1: {"api"}},
+		},
+		"NDJSON": {
+			name: "services.ndjson",
+			content: `{"apiVersion": "openslo/v1", "kind": "Service", "metadata": {"name": "web"}, "spec": {}}
+
+{"apiVersion": "openslo/v1", "kind": "Service", "metadata": {"name": "api"}, "spec": {}}
+`,
+			want: map[int][]string{0: {"web"}, 1: {"api"}},
+		},
+		"sniffed format": {
+			name:    "services",
+			content: `[{"apiVersion": "openslo/v1", "kind": "Service", "metadata": {"name": "web"}, "spec": {}}]`,
+			want:    map[int][]string{0: {"web"}},
+		},
+		"error reports document index": {
+			name: "services.yaml",
+			content: `apiVersion: openslo/v1
+kind: Service
+metadata:
+  name: web
+spec: {}
+---
+apiVersion: openslo/v1
+kind: Service
+metadata:
+  name: api
+spec:
+  unknownField: true
+`,
+			wantErr: `document 1 at line 7: failed to decode openslo/v1 Service: json: unknown field "unknownField"`,
+		},
+		"JSON error reports document index": {
+			name: "services.json",
+			content: `[
+  {"apiVersion": "openslo/v1", "kind": "Service", "metadata": {"name": "web"}, "spec": {}},
+  {"apiVersion": "openslo/v1", "kind": "Service", "metadata": {"name": "api"}, "spec": {"unknownField": true}}
+]`,
+			wantErr: `document 1: failed to decode openslo/v1 Service: json: unknown field "unknownField"`,
+		},
+		"size limit": {
+			name: "services.yaml",
+			content: `apiVersion: openslo/v1
+kind: Service
+metadata:
+  name: web
+spec: {}
+`,
+			opts:    files.ReadOptions{MaxFileSize: 10},
+			wantErr: "exceeds the maximum size of 10 bytes",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			path := filepath.Join(t.TempDir(), tc.name)
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+
+			got := make(map[int][]string)
+			err := files.StreamObjects(context.Background(), path, tc.opts, func(index int, objects []openslo.Object) error {
+				for _, o := range objects {
+					got[index] = append(got[index], o.GetName())
+				}
+				return nil
+			})
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestFormat_Stream(t *testing.T) {
+	t.Parallel()
+	sources := []string{
+		"../../test/inputs/validate/v1.yaml",
+		"../../test/inputs/validate/v1alpha.yaml",
+		"../../test/inputs/validate/v2alpha.yaml",
+		"testdata/format/list-of-services.yaml",
+		"testdata/format/valid-service.json",
+		"testdata/stream/slos.yaml",
+		"testdata/format/objects.ndjson",
+	}
+	for _, format := range []openslosdk.ObjectFormat{openslosdk.FormatYAML, openslosdk.FormatJSON} {
+		for _, normalize := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s normalize=%t", format, normalize), func(t *testing.T) {
+				t.Parallel()
+				want, wantReport := new(bytes.Buffer), new(bytes.Buffer)
+				err := files.Format(context.Background(), want, format, sources, files.FormatOptions{
+					Normalize: normalize,
+					Report:    wantReport,
+				})
+				require.NoError(t, err)
+
+				got, gotReport := new(bytes.Buffer), new(bytes.Buffer)
+				err = files.Format(context.Background(), got, format, sources, files.FormatOptions{
+					Normalize: normalize,
+					Report:    gotReport,
+					Stream:    true,
+				})
+				require.NoError(t, err)
+				assert.Equal(t, want.String(), got.String())
+				assert.Equal(t, wantReport.String(), gotReport.String())
+				if normalize {
+					assert.Contains(t, gotReport.String(), `line 31: spec.timeWindow[0].duration: "14d" -> "2w"`)
+					// Lines of NDJSON sources are reported as they are in the source.
+					assert.Contains(t, gotReport.String(),
+						`objects.ndjson: line 4: spec.timeWindow[0].duration: "14d" -> "2w"`)
+				}
+			})
+		}
+	}
+}
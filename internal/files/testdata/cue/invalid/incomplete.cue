@@ -0,0 +1,4 @@
+apiVersion: "openslo/v1"
+kind:       "Service"
+metadata: name: string
+spec: {}
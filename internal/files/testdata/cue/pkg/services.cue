@@ -0,0 +1,10 @@
+package slos
+
+services: [Name=string]: {
+	apiVersion: "openslo/v1"
+	kind:       "Service"
+	metadata: name: Name
+	spec: description: *"Owned by \(#team)" | string
+}
+
+services: web: {}
@@ -0,0 +1,15 @@
+package slos
+
+#team: "platform"
+
+services: api: spec: description: "Public API"
+
+datasource: {
+	apiVersion: "openslo/v1"
+	kind:       "DataSource"
+	metadata: name: "prometheus"
+	spec: {
+		type: "Prometheus"
+		connectionDetails: url: "http://prometheus:9090"
+	}
+}
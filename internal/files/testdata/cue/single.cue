@@ -0,0 +1,8 @@
+_env: "prod"
+
+[for svc in ["web", "api"] {
+	apiVersion: "openslo/v1"
+	kind:       "Service"
+	metadata: name: "\(svc)-\(_env)"
+	spec: description: "Service \(svc)"
+}]
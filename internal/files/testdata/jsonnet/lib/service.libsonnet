@@ -0,0 +1,8 @@
+{
+  service(name, description):: {
+    apiVersion: 'openslo/v1',
+    kind: 'Service',
+    metadata: { name: name },
+    spec: { description: description },
+  },
+}
@@ -0,0 +1,244 @@
+// Package normalize rewrites values which can be expressed in many equivalent ways,
+// like durations, numbers and timestamps, to a single canonical representation.
+package normalize
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+
+	v1 "github.com/OpenSLO/go-sdk/pkg/openslo/v1"
+)
+
+// Rewrite describes a single value rewritten by [Normalize].
+type Rewrite struct {
+	// Line is the line number of the value in the original data.
+	Line int
+	// Path is the path to the rewritten value within the document, e.g. spec.timeWindow[0].duration.
+	Path string
+	// Old is the original representation of the value.
+	Old string
+	// New is the canonical representation of the value.
+	New string
+}
+
+func (r Rewrite) String() string {
+	return fmt.Sprintf("line %d: %s: %q -> %q", r.Line, r.Path, r.Old, r.New)
+}
+
+// Normalize rewrites durations, numbers and calendar start times found in the provided
+// YAML or JSON data to their canonical representation.
+// Every rewrite is verified to be semantically equal to the original value
+// and the values which cannot be proven equal are left untouched.
+// The returned data is always encoded as YAML.
+func Normalize(data []byte) ([]byte, []Rewrite, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	buf := new(bytes.Buffer)
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	var rewrites []Rewrite
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, nil, fmt.Errorf("failed to decode data: %w", err)
+		}
+		n := &normalizer{}
+		n.walk(&doc, "", "")
+		rewrites = append(rewrites, n.rewrites...)
+		if err := enc.Encode(&doc); err != nil {
+			return nil, nil, fmt.Errorf("failed to encode normalized data: %w", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode normalized data: %w", err)
+	}
+	return buf.Bytes(), rewrites, nil
+}
+
+type normalizer struct {
+	rewrites []Rewrite
+}
+
+// durationKeys lists the keys which hold [v1.DurationShorthand] values.
+var durationKeys = map[string]bool{
+	"duration":        true,
+	"timeSliceWindow": true,
+	"lookbackWindow":  true,
+	"alertAfter":      true,
+}
+
+const startTimeKey = "startTime"
+
+func (n *normalizer) walk(node *yaml.Node, path, key string) {
+	// Flow style is dropped so that JSON input is always emitted as block YAML.
+	node.Style &^= yaml.FlowStyle
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			n.walk(child, path, key)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			n.walk(child, fmt.Sprintf("%s[%d]", path, i), "")
+		}
+	case yaml.MappingNode:
+		calendarWindow := isCalendarTimeWindow(node)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, v := node.Content[i], node.Content[i+1]
+			// Calendar aligned windows depend on the unit they were defined with,
+			// for instance 1w starts on Monday while 7d does not.
+			if calendarWindow && k.Value == "duration" {
+				continue
+			}
+			childPath := k.Value
+			if path != "" {
+				childPath = path + "." + k.Value
+			}
+			n.walk(v, childPath, k.Value)
+		}
+	case yaml.ScalarNode:
+		n.normalizeScalar(node, path, key)
+	case yaml.AliasNode:
+	}
+}
+
+func (n *normalizer) normalizeScalar(node *yaml.Node, path, key string) {
+	var (
+		normalized string
+		ok         bool
+	)
+	switch {
+	case durationKeys[key] && node.ShortTag() == "!!str":
+		normalized, ok = normalizeDuration(node.Value)
+	case key == startTimeKey && node.ShortTag() == "!!str":
+		normalized, ok = normalizeTime(node.Value)
+	case node.ShortTag() == "!!float":
+		normalized, ok = normalizeFloat(node.Value)
+		if ok {
+			// Let the encoder resolve the tag, canonical floats may look like integers.
+			node.Tag = ""
+		}
+	}
+	if !ok || normalized == node.Value {
+		return
+	}
+	n.rewrites = append(n.rewrites, Rewrite{
+		Line: node.Line,
+		Path: path,
+		Old:  node.Value,
+		New:  normalized,
+	})
+	node.Value = normalized
+}
+
+// isCalendarTimeWindow reports whether the mapping node is a time window which is not rolling.
+func isCalendarTimeWindow(node *yaml.Node) bool {
+	hasDuration, isRolling, hasCalendar := false, false, false
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		k, v := node.Content[i], node.Content[i+1]
+		switch k.Value {
+		case "duration":
+			hasDuration = true
+		case "isRolling":
+			isRolling = v.Value == "true"
+		case "calendar":
+			hasCalendar = true
+		}
+	}
+	return hasDuration && (hasCalendar || !isRolling)
+}
+
+var durationShorthandRegex = regexp.MustCompile(`^(\d+)([mhdw])$`)
+
+// fixedDurationUnits lists units with a fixed length, from the largest to the smallest.
+// Units like month, quarter or year are not fixed, thus they're never rewritten.
+var fixedDurationUnits = []struct {
+	unit    v1.DurationShorthandUnit
+	minutes int
+}{
+	{v1.DurationShorthandUnitWeek, 7 * 24 * 60},
+	{v1.DurationShorthandUnitDay, 24 * 60},
+	{v1.DurationShorthandUnitHour, 60},
+	{v1.DurationShorthandUnitMinute, 1},
+}
+
+// normalizeDuration converts the duration shorthand to the largest unit which expresses it exactly.
+func normalizeDuration(s string) (string, bool) {
+	matches := durationShorthandRegex.FindStringSubmatch(s)
+	if matches == nil {
+		return "", false
+	}
+	original, err := v1.ParseDurationShorthand(s)
+	if err != nil || original.GetValue() <= 0 {
+		return "", false
+	}
+	minutes := int(original.Duration() / time.Minute)
+	for _, u := range fixedDurationUnits {
+		if minutes%u.minutes != 0 {
+			continue
+		}
+		normalized := v1.NewDurationShorthand(minutes/u.minutes, u.unit)
+		if normalized.Duration() != original.Duration() {
+			return "", false
+		}
+		return normalized.String(), true
+	}
+	return "", false
+}
+
+// startTimeLayouts lists accepted layouts of calendar start time.
+// Layouts with a time zone offset are not listed on purpose,
+// the time zone is defined by a separate field and rewriting them would change their meaning.
+var startTimeLayouts = []string{
+	time.DateTime,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	time.DateOnly,
+}
+
+// normalizeTime converts the time to [time.DateTime] layout expected by the OpenSLO specification.
+func normalizeTime(s string) (string, bool) {
+	for _, layout := range startTimeLayouts {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		normalized := t.Format(time.DateTime)
+		if parsed, err := time.Parse(time.DateTime, normalized); err != nil || !parsed.Equal(t) {
+			return "", false
+		}
+		return normalized, true
+	}
+	return "", false
+}
+
+// normalizeFloat formats the number the same way encoding/json does.
+func normalizeFloat(s string) (string, bool) {
+	var f float64
+	if err := yaml.Unmarshal([]byte(s), &f); err != nil {
+		return "", false
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", false
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	normalized := strconv.FormatFloat(f, format, -1, 64)
+	if parsed, err := strconv.ParseFloat(normalized, 64); err != nil || parsed != f {
+		return "", false
+	}
+	return normalized, true
+}
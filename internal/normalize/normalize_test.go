@@ -0,0 +1,127 @@
+package normalize_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenSLO/oslo/internal/normalize"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		input        string
+		wantOut      string
+		wantRewrites []normalize.Rewrite
+	}{
+		{
+			name: "rolling window duration",
+			input: `spec:
+  timeWindow:
+    - duration: 7d
+      isRolling: true
+`,
+			wantOut: `spec:
+  timeWindow:
+    - duration: 1w
+      isRolling: true
+`,
+			wantRewrites: []normalize.Rewrite{
+				{Line: 3, Path: "spec.timeWindow[0].duration", Old: "7d", New: "1w"},
+			},
+		},
+		{
+			name: "calendar window duration is not rewritten",
+			input: `spec:
+  timeWindow:
+    - duration: 7d
+      isRolling: false
+      calendar:
+        startTime: 2022-01-01T12:00
+        timeZone: UTC
+`,
+			wantOut: `spec:
+  timeWindow:
+    - duration: 7d
+      isRolling: false
+      calendar:
+        startTime: "2022-01-01 12:00:00"
+        timeZone: UTC
+`,
+			wantRewrites: []normalize.Rewrite{
+				{Line: 6, Path: "spec.timeWindow[0].calendar.startTime", Old: "2022-01-01T12:00", New: "2022-01-01 12:00:00"},
+			},
+		},
+		{
+			name: "non fixed and already canonical durations",
+			input: `spec:
+  lookbackWindow: 90m
+  alertAfter: 1M
+  timeSliceWindow: 120m
+`,
+			wantOut: `spec:
+  lookbackWindow: 90m
+  alertAfter: 1M
+  timeSliceWindow: 2h
+`,
+			wantRewrites: []normalize.Rewrite{
+				{Line: 4, Path: "spec.timeSliceWindow", Old: "120m", New: "2h"},
+			},
+		},
+		{
+			name: "numbers",
+			input: `spec:
+  objectives:
+    - target: 99e-2
+      value: 1.0
+      timeSliceTarget: 0.95
+`,
+			wantOut: `spec:
+  objectives:
+    - target: 0.99
+      value: 1
+      timeSliceTarget: 0.95
+`,
+			wantRewrites: []normalize.Rewrite{
+				{Line: 3, Path: "spec.objectives[0].target", Old: "99e-2", New: "0.99"},
+				{Line: 4, Path: "spec.objectives[0].value", Old: "1.0", New: "1"},
+			},
+		},
+		{
+			name:  "time with offset is not rewritten",
+			input: `{"calendar": {"startTime": "2022-01-01T12:00:00Z"}}`,
+			wantOut: `"calendar":
+  "startTime": "2022-01-01T12:00:00Z"
+`,
+		},
+		{
+			name: "multiple documents",
+			input: `target: 1e0
+---
+duration: 60m
+isRolling: true
+`,
+			wantOut: `target: 1
+---
+duration: 1h
+isRolling: true
+`,
+			wantRewrites: []normalize.Rewrite{
+				{Line: 1, Path: "target", Old: "1e0", New: "1"},
+				{Line: 3, Path: "duration", Old: "60m", New: "1h"},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			out, rewrites, err := normalize.Normalize([]byte(tc.input))
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantOut, string(out))
+			assert.Equal(t, tc.wantRewrites, rewrites)
+		})
+	}
+}
@@ -0,0 +1,289 @@
+// Package prometheus generates Prometheus recording and alerting rules for SLOs
+// whose indicators are backed by Prometheus DataSources.
+//
+// For every objective of such an SLO three rule groups are generated:
+//   - SLI recordings, the error ratio of the indicator over every window used by alerts,
+//   - meta recordings, the objective, error budget and remaining error budget over the SLO time window,
+//   - alerts, multi-window multi-burn-rate alerts built from the SLO's AlertPolicies
+//     or, if it has none, from defaults which page when 2% of the error budget is spent within an hour
+//     or 5% within 6 hours and open a ticket when 10% is spent within a day or 3 days.
+package prometheus
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/OpenSLO/go-sdk/pkg/openslo"
+	v1 "github.com/OpenSLO/go-sdk/pkg/openslo/v1"
+	"github.com/OpenSLO/go-sdk/pkg/openslosdk"
+
+	"github.com/OpenSLO/oslo/internal/convert"
+)
+
+// DataSourceType is the type of DataSources, and metric sources, rules are generated for.
+const DataSourceType = "Prometheus"
+
+// Labels added to every generated rule.
+const (
+	LabelSLO            = "openslo_slo"
+	LabelService        = "openslo_service"
+	LabelObjective      = "openslo_objective"
+	LabelAlertPolicy    = "openslo_alert_policy"
+	LabelAlertCondition = "openslo_alert_condition"
+	LabelLongWindow     = "openslo_long_window"
+	LabelShortWindow    = "openslo_short_window"
+	LabelSeverity       = "severity"
+)
+
+// Names of the recorded metrics, the error ratio is suffixed with its window, e.g. 5m.
+const (
+	MetricSLIErrorRatio        = "openslo:sli_error:ratio_rate"
+	MetricObjective            = "openslo:objective:ratio"
+	MetricErrorBudget          = "openslo:error_budget:ratio"
+	MetricErrorBudgetRemaining = "openslo:error_budget_remaining:ratio"
+)
+
+// Names of the generated alerts.
+const (
+	AlertErrorBudgetBurn = "OpenSLOErrorBudgetBurn"
+	AlertNoData          = "OpenSLONoData"
+)
+
+const (
+	// baseWindow is the shortest window the error ratio is recorded over.
+	baseWindow = 5 * time.Minute
+	// shortWindowDivisor is the ratio between the long and the short window of AlertCondition burn rate alerts.
+	shortWindowDivisor = 12
+	noDataAlertFor     = 10 * time.Minute
+)
+
+// RuleFile is a Prometheus rule file.
+type RuleFile struct {
+	Groups []RuleGroup `yaml:"groups"`
+}
+
+// RuleGroup is a group of rules evaluated sequentially at the same interval.
+type RuleGroup struct {
+	Name  string `yaml:"name"`
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule is either a recording or an alerting rule.
+type Rule struct {
+	Record      string            `yaml:"record,omitempty"`
+	Alert       string            `yaml:"alert,omitempty"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// Encode writes the rule file as YAML.
+func (f RuleFile) Encode(out io.Writer) error {
+	if f.Groups == nil {
+		f.Groups = []RuleGroup{}
+	}
+	enc := yaml.NewEncoder(out)
+	enc.SetIndent(2)
+	if err := enc.Encode(f); err != nil {
+		return fmt.Errorf("failed to encode rule file: %w", err)
+	}
+	return enc.Close()
+}
+
+// Warning describes an SLO, or a part of it, which rules were not generated for.
+type Warning struct {
+	// SLO is the name of the SLO.
+	SLO     string
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("SLO '%s': %s", w.SLO, w.Message)
+}
+
+// Generate builds rule groups for every SLO backed by Prometheus DataSources.
+// Objects in versions other than openslo/v1 are converted to it first
+// and references to SLIs, AlertPolicies and AlertConditions are resolved among the objects.
+// SLOs which are not backed by Prometheus are skipped silently,
+// while those which are but cannot be expressed as rules are skipped with a [Warning].
+func Generate(objects []openslo.Object) (RuleFile, []Warning, error) {
+	converted := make([]openslo.Object, 0, len(objects))
+	for _, object := range objects {
+		if object.GetVersion() != openslo.VersionV1 {
+			var err error
+			if object, _, err = convert.Convert(object, openslo.VersionV1); err != nil {
+				return RuleFile{}, nil, err
+			}
+		}
+		converted = append(converted, object)
+	}
+	inlined, err := openslosdk.NewReferenceInliner(converted...).
+		WithConfig(openslosdk.ReferenceConfig{
+			V1: openslosdk.ReferenceConfigV1{
+				SLO:         openslosdk.ReferenceConfigV1SLO{AlertPolicy: true, SLI: true},
+				AlertPolicy: openslosdk.ReferenceConfigV1AlertPolicy{AlertCondition: true},
+			},
+		}).
+		Inline()
+	if err != nil {
+		return RuleFile{}, nil, fmt.Errorf("failed to resolve references: %w", err)
+	}
+
+	g := &generator{dataSources: make(map[string]v1.DataSource)}
+	for _, dataSource := range openslosdk.FilterByType[v1.DataSource](inlined) {
+		g.dataSources[dataSource.Metadata.Name] = dataSource
+	}
+	var file RuleFile
+	for _, slo := range openslosdk.FilterByType[v1.SLO](inlined) {
+		groups, err := g.generateSLO(slo)
+		if err != nil {
+			return RuleFile{}, nil, fmt.Errorf("%s: %w", slo, err)
+		}
+		file.Groups = append(file.Groups, groups...)
+	}
+	return file, g.warnings, nil
+}
+
+type generator struct {
+	dataSources map[string]v1.DataSource
+	warnings    []Warning
+}
+
+func (g *generator) warn(slo v1.SLO, format string, a ...any) {
+	g.warnings = append(g.warnings, Warning{SLO: slo.Metadata.Name, Message: fmt.Sprintf(format, a...)})
+}
+
+func (g *generator) generateSLO(slo v1.SLO) ([]RuleGroup, error) {
+	backed, err := g.isBackedByPrometheus(slo)
+	if err != nil || !backed {
+		return nil, err
+	}
+	switch {
+	case slo.Spec.Indicator == nil || slo.Spec.HasCompositeObjectives():
+		g.warn(slo, "skipped, composite SLOs are not supported")
+		return nil, nil
+	case slo.Spec.BudgetingMethod != v1.SLOBudgetingMethodOccurrences:
+		g.warn(slo, "skipped, %s budgeting method is not supported", slo.Spec.BudgetingMethod)
+		return nil, nil
+	case len(slo.Spec.TimeWindow) != 1:
+		g.warn(slo, "skipped, exactly one time window is required")
+		return nil, nil
+	}
+	timeWindow := slo.Spec.TimeWindow[0]
+	period := timeWindow.Duration.Duration()
+	if !timeWindow.IsRolling {
+		g.warn(slo, "calendar time window is approximated by a rolling window of %s", formatDuration(period))
+	}
+
+	alerts := g.burnRateAlerts(slo, period)
+	noDataAlerts := noDataAlerts(slo)
+	var groups []RuleGroup
+	for i, objective := range slo.Spec.Objectives {
+		path := fmt.Sprintf("spec.objectives[%d]", i)
+		target := objectiveTarget(objective)
+		if target == nil {
+			g.warn(slo, "%s: skipped, target is required", path)
+			continue
+		}
+		errorRatio, err := errorRatioQuery(*slo.Spec.Indicator, objective)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		o := objectiveRules{
+			slo:          slo,
+			name:         objectiveName(i, objective),
+			target:       *target,
+			period:       period,
+			errorRatio:   errorRatio,
+			alerts:       alerts,
+			noDataAlerts: noDataAlerts,
+		}
+		groups = append(groups, o.groups()...)
+	}
+	return groups, nil
+}
+
+// isBackedByPrometheus reports whether all metric sources of the SLO are of the Prometheus type.
+// SLOs which mix Prometheus and other metric sources are reported.
+func (g *generator) isBackedByPrometheus(slo v1.SLO) (bool, error) {
+	var types []string
+	for _, spec := range metricSpecs(slo) {
+		source := spec.MetricSource
+		sourceType := source.Type
+		if source.MetricSourceRef != "" {
+			dataSource, ok := g.dataSources[source.MetricSourceRef]
+			if !ok {
+				return false, fmt.Errorf("DataSource '%s' referenced at metricSourceRef does not exist",
+					source.MetricSourceRef)
+			}
+			sourceType = dataSource.Spec.Type
+		}
+		types = append(types, sourceType)
+	}
+	prometheusTypes := 0
+	for _, sourceType := range types {
+		if strings.EqualFold(sourceType, DataSourceType) {
+			prometheusTypes++
+		}
+	}
+	switch {
+	case prometheusTypes == 0:
+		return false, nil
+	case prometheusTypes < len(types):
+		g.warn(slo, "skipped, metric sources other than %s are not supported", DataSourceType)
+		return false, nil
+	default:
+		return true, nil
+	}
+}
+
+// metricSpecs returns all metric specs of the SLO's indicators.
+func metricSpecs(slo v1.SLO) []v1.SLIMetricSpec {
+	indicators := make([]*v1.SLOIndicatorInline, 0, len(slo.Spec.Objectives)+1)
+	indicators = append(indicators, slo.Spec.Indicator)
+	for _, objective := range slo.Spec.Objectives {
+		indicators = append(indicators, objective.Indicator)
+	}
+	var specs []v1.SLIMetricSpec
+	for _, indicator := range indicators {
+		if indicator == nil {
+			continue
+		}
+		spec := indicator.Spec
+		metrics := []*v1.SLIMetricSpec{spec.ThresholdMetric}
+		if spec.RatioMetric != nil {
+			metrics = append(metrics, spec.RatioMetric.Good, spec.RatioMetric.Bad, spec.RatioMetric.Total, spec.RatioMetric.Raw)
+		}
+		for _, metric := range metrics {
+			if metric != nil {
+				specs = append(specs, *metric)
+			}
+		}
+	}
+	return specs
+}
+
+func objectiveName(index int, objective v1.SLOObjective) string {
+	if objective.DisplayName != "" {
+		return objective.DisplayName
+	}
+	return fmt.Sprint(index)
+}
+
+// objectiveTarget returns the target of the objective as a ratio, or nil if it has none.
+func objectiveTarget(objective v1.SLOObjective) *float64 {
+	switch {
+	case objective.Target != nil:
+		return objective.Target
+	case objective.TargetPercent != nil:
+		target := *objective.TargetPercent / 100
+		return &target
+	default:
+		return nil
+	}
+}
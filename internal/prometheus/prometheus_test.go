@@ -0,0 +1,510 @@
+package prometheus_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenSLO/go-sdk/pkg/openslosdk"
+
+	"github.com/OpenSLO/oslo/internal/prometheus"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		input        string
+		wantOut      string
+		wantWarnings []prometheus.Warning
+		wantErr      string
+	}{
+		{
+			name: "counter ratio SLO with alert policy",
+			input: `- apiVersion: openslo/v1
+  kind: DataSource
+  metadata:
+    name: prometheus
+  spec:
+    type: Prometheus
+    connectionDetails:
+      url: http://prometheus:9090
+- apiVersion: openslo/v1
+  kind: SLO
+  metadata:
+    name: api
+  spec:
+    service: web
+    budgetingMethod: Occurrences
+    timeWindow:
+      - duration: 28d
+        isRolling: true
+    indicator:
+      metadata:
+        name: api-availability
+      spec:
+        ratioMetric:
+          counter: true
+          good:
+            metricSource:
+              metricSourceRef: prometheus
+              spec:
+                query: http_requests_total{code!~"5.."}
+          total:
+            metricSource:
+              metricSourceRef: prometheus
+              spec:
+                query: http_requests_total
+    objectives:
+      - displayName: availability
+        target: 0.99
+    alertPolicies:
+      - kind: AlertPolicy
+        metadata:
+          name: fast-burn
+        spec:
+          alertWhenNoData: true
+          conditions:
+            - conditionRef: burn-2h
+          notificationTargets:
+            - targetRef: pager
+- apiVersion: openslo/v1
+  kind: AlertCondition
+  metadata:
+    name: burn-2h
+  spec:
+    severity: critical
+    description: API burns its error budget.
+    condition:
+      kind: burnrate
+      op: gte
+      threshold: 10
+      lookbackWindow: 2h
+      alertAfter: 5m
+`,
+			wantOut: `groups:
+  - name: openslo-api-availability-sli-recordings
+    rules:
+      - record: openslo:sli_error:ratio_rate5m
+        expr: 1 - ((sum(rate(http_requests_total{code!~"5.."}[5m]))) / (sum(rate(http_requests_total[5m]))))
+        labels:
+          openslo_objective: availability
+          openslo_service: web
+          openslo_slo: api
+      - record: openslo:sli_error:ratio_rate10m
+        expr: 1 - ((sum(rate(http_requests_total{code!~"5.."}[10m]))) / (sum(rate(http_requests_total[10m]))))
+        labels:
+          openslo_objective: availability
+          openslo_service: web
+          openslo_slo: api
+      - record: openslo:sli_error:ratio_rate2h
+        expr: 1 - ((sum(rate(http_requests_total{code!~"5.."}[2h]))) / (sum(rate(http_requests_total[2h]))))
+        labels:
+          openslo_objective: availability
+          openslo_service: web
+          openslo_slo: api
+  - name: openslo-api-availability-meta-recordings
+    rules:
+      - record: openslo:objective:ratio
+        expr: vector(0.99)
+        labels:
+          openslo_objective: availability
+          openslo_service: web
+          openslo_slo: api
+      - record: openslo:error_budget:ratio
+        expr: vector(0.01)
+        labels:
+          openslo_objective: availability
+          openslo_service: web
+          openslo_slo: api
+      - record: openslo:sli_error:ratio_rate4w
+        expr: avg_over_time(openslo:sli_error:ratio_rate5m{openslo_slo="api", openslo_service="web", openslo_objective="availability"}[4w])
+        labels:
+          openslo_objective: availability
+          openslo_service: web
+          openslo_slo: api
+      - record: openslo:error_budget_remaining:ratio
+        expr: 1 - (openslo:sli_error:ratio_rate4w{openslo_slo="api", openslo_service="web", openslo_objective="availability"} / 0.01)
+        labels:
+          openslo_objective: availability
+          openslo_service: web
+          openslo_slo: api
+  - name: openslo-api-availability-alerts
+    rules:
+      - alert: OpenSLOErrorBudgetBurn
+        expr: openslo:sli_error:ratio_rate2h{openslo_slo="api", openslo_service="web", openslo_objective="availability"} >= (10 * 0.01) and openslo:sli_error:ratio_rate10m{openslo_slo="api", openslo_service="web", openslo_objective="availability"} >= (10 * 0.01)
+        for: 5m
+        labels:
+          openslo_alert_condition: burn-2h
+          openslo_alert_policy: fast-burn
+          openslo_long_window: 2h
+          openslo_objective: availability
+          openslo_service: web
+          openslo_short_window: 10m
+          openslo_slo: api
+          severity: critical
+        annotations:
+          description: API burns its error budget.
+          summary: SLO 'api' objective 'availability' of service 'web' is burning its error budget too fast.
+      - alert: OpenSLONoData
+        expr: absent(openslo:sli_error:ratio_rate5m{openslo_slo="api", openslo_service="web", openslo_objective="availability"})
+        for: 10m
+        labels:
+          openslo_alert_policy: fast-burn
+          openslo_objective: availability
+          openslo_service: web
+          openslo_slo: api
+          severity: critical
+        annotations:
+          summary: SLO 'api' objective 'availability' of service 'web' has no data.
+`,
+		},
+		{
+			name: "gauge raw SLO with default alerts",
+			input: `- apiVersion: openslo/v1
+  kind: SLO
+  metadata:
+    name: jobs
+  spec:
+    service: batch
+    budgetingMethod: Occurrences
+    timeWindow:
+      - duration: 1d
+        isRolling: true
+    indicator:
+      metadata:
+        name: job-failures
+      spec:
+        ratioMetric:
+          counter: false
+          rawType: failure
+          raw:
+            metricSource:
+              type: prometheus
+              spec:
+                query: max(max_over_time(job_failure_ratio[{{ .window }}]))
+    objectives:
+      - targetPercent: 95
+`,
+			wantOut: `groups:
+  - name: openslo-jobs-0-sli-recordings
+    rules:
+      - record: openslo:sli_error:ratio_rate5m
+        expr: max(max_over_time(job_failure_ratio[5m]))
+        labels:
+          openslo_objective: "0"
+          openslo_service: batch
+          openslo_slo: jobs
+      - record: openslo:sli_error:ratio_rate30m
+        expr: max(max_over_time(job_failure_ratio[30m]))
+        labels:
+          openslo_objective: "0"
+          openslo_service: batch
+          openslo_slo: jobs
+      - record: openslo:sli_error:ratio_rate1h
+        expr: max(max_over_time(job_failure_ratio[1h]))
+        labels:
+          openslo_objective: "0"
+          openslo_service: batch
+          openslo_slo: jobs
+      - record: openslo:sli_error:ratio_rate2h
+        expr: max(max_over_time(job_failure_ratio[2h]))
+        labels:
+          openslo_objective: "0"
+          openslo_service: batch
+          openslo_slo: jobs
+      - record: openslo:sli_error:ratio_rate6h
+        expr: max(max_over_time(job_failure_ratio[6h]))
+        labels:
+          openslo_objective: "0"
+          openslo_service: batch
+          openslo_slo: jobs
+  - name: openslo-jobs-0-meta-recordings
+    rules:
+      - record: openslo:objective:ratio
+        expr: vector(0.95)
+        labels:
+          openslo_objective: "0"
+          openslo_service: batch
+          openslo_slo: jobs
+      - record: openslo:error_budget:ratio
+        expr: vector(0.05)
+        labels:
+          openslo_objective: "0"
+          openslo_service: batch
+          openslo_slo: jobs
+      - record: openslo:sli_error:ratio_rate1d
+        expr: avg_over_time(openslo:sli_error:ratio_rate5m{openslo_slo="jobs", openslo_service="batch", openslo_objective="0"}[1d])
+        labels:
+          openslo_objective: "0"
+          openslo_service: batch
+          openslo_slo: jobs
+      - record: openslo:error_budget_remaining:ratio
+        expr: 1 - (openslo:sli_error:ratio_rate1d{openslo_slo="jobs", openslo_service="batch", openslo_objective="0"} / 0.05)
+        labels:
+          openslo_objective: "0"
+          openslo_service: batch
+          openslo_slo: jobs
+  - name: openslo-jobs-0-alerts
+    rules:
+      - alert: OpenSLOErrorBudgetBurn
+        expr: openslo:sli_error:ratio_rate1h{openslo_slo="jobs", openslo_service="batch", openslo_objective="0"} > (0.48 * 0.05) and openslo:sli_error:ratio_rate5m{openslo_slo="jobs", openslo_service="batch", openslo_objective="0"} > (0.48 * 0.05)
+        labels:
+          openslo_long_window: 1h
+          openslo_objective: "0"
+          openslo_service: batch
+          openslo_short_window: 5m
+          openslo_slo: jobs
+          severity: page
+        annotations:
+          description: Error budget burn rate is > 0.48 over the last 1h and 5m.
+          summary: SLO 'jobs' objective '0' of service 'batch' is burning its error budget too fast.
+      - alert: OpenSLOErrorBudgetBurn
+        expr: openslo:sli_error:ratio_rate6h{openslo_slo="jobs", openslo_service="batch", openslo_objective="0"} > (0.2 * 0.05) and openslo:sli_error:ratio_rate30m{openslo_slo="jobs", openslo_service="batch", openslo_objective="0"} > (0.2 * 0.05)
+        labels:
+          openslo_long_window: 6h
+          openslo_objective: "0"
+          openslo_service: batch
+          openslo_short_window: 30m
+          openslo_slo: jobs
+          severity: page
+        annotations:
+          description: Error budget burn rate is > 0.2 over the last 6h and 30m.
+          summary: SLO 'jobs' objective '0' of service 'batch' is burning its error budget too fast.
+      - alert: OpenSLOErrorBudgetBurn
+        expr: openslo:sli_error:ratio_rate1d{openslo_slo="jobs", openslo_service="batch", openslo_objective="0"} > (0.1 * 0.05) and openslo:sli_error:ratio_rate2h{openslo_slo="jobs", openslo_service="batch", openslo_objective="0"} > (0.1 * 0.05)
+        labels:
+          openslo_long_window: 1d
+          openslo_objective: "0"
+          openslo_service: batch
+          openslo_short_window: 2h
+          openslo_slo: jobs
+          severity: ticket
+        annotations:
+          description: Error budget burn rate is > 0.1 over the last 1d and 2h.
+          summary: SLO 'jobs' objective '0' of service 'batch' is burning its error budget too fast.
+`,
+		},
+		{
+			name: "SLOs not backed by Prometheus are ignored",
+			input: `- apiVersion: openslo/v1
+  kind: SLO
+  metadata:
+    name: api
+  spec:
+    service: web
+    budgetingMethod: Occurrences
+    timeWindow:
+      - duration: 28d
+        isRolling: true
+    indicator:
+      metadata:
+        name: latency
+      spec:
+        thresholdMetric:
+          metricSource:
+            type: Datadog
+            spec:
+              query: avg:trace.http.request.duration{service:web}
+    objectives:
+      - op: lt
+        value: 0.2
+        target: 0.99
+`,
+			wantOut: "groups: []\n",
+		},
+		{
+			name: "unsupported SLOs are reported",
+			input: `- apiVersion: openslo/v1
+  kind: SLO
+  metadata:
+    name: timeslices
+  spec:
+    service: web
+    budgetingMethod: Timeslices
+    timeWindow:
+      - duration: 28d
+        isRolling: true
+    indicator:
+      metadata:
+        name: latency
+      spec:
+        thresholdMetric:
+          metricSource:
+            type: Prometheus
+            spec:
+              query: web_latency_seconds
+    objectives:
+      - op: lt
+        value: 0.2
+        target: 0.99
+        timeSliceTarget: 0.9
+        timeSliceWindow: 1m
+- apiVersion: openslo/v1
+  kind: SLO
+  metadata:
+    name: mixed
+  spec:
+    service: web
+    budgetingMethod: Occurrences
+    timeWindow:
+      - duration: 28d
+        isRolling: true
+    indicator:
+      metadata:
+        name: availability
+      spec:
+        ratioMetric:
+          counter: true
+          bad:
+            metricSource:
+              type: Prometheus
+              spec:
+                query: http_errors_total
+          total:
+            metricSource:
+              type: Datadog
+              spec:
+                query: sum:http.requests{*}.as_count()
+    objectives:
+      - target: 0.99
+`,
+			wantOut: "groups: []\n",
+			wantWarnings: []prometheus.Warning{
+				{SLO: "timeslices", Message: "skipped, Timeslices budgeting method is not supported"},
+				{SLO: "mixed", Message: "skipped, metric sources other than Prometheus are not supported"},
+			},
+		},
+		{
+			name: "counter query which is not a selector",
+			input: `- apiVersion: openslo/v1
+  kind: SLO
+  metadata:
+    name: api
+  spec:
+    service: web
+    budgetingMethod: Occurrences
+    timeWindow:
+      - duration: 28d
+        isRolling: true
+    indicator:
+      metadata:
+        name: availability
+      spec:
+        ratioMetric:
+          counter: true
+          bad:
+            metricSource:
+              type: Prometheus
+              spec:
+                query: sum(http_errors_total)
+          total:
+            metricSource:
+              type: Prometheus
+              spec:
+                query: http_requests_total
+    objectives:
+      - target: 0.99
+`,
+			wantErr: "v1.SLO 'api': spec.objectives[0]: bad metric query must be a vector selector " +
+				"or use the {{.window}} placeholder for counters",
+		},
+		{
+			name: "missing data source",
+			input: `- apiVersion: openslo/v1
+  kind: SLO
+  metadata:
+    name: api
+  spec:
+    service: web
+    budgetingMethod: Occurrences
+    timeWindow:
+      - duration: 28d
+        isRolling: true
+    indicator:
+      metadata:
+        name: latency
+      spec:
+        thresholdMetric:
+          metricSource:
+            metricSourceRef: prometheus
+            spec:
+              query: web_latency_seconds
+    objectives:
+      - op: lt
+        value: 0.2
+        target: 0.99
+`,
+			wantErr: "v1.SLO 'api': DataSource 'prometheus' referenced at metricSourceRef does not exist",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			objects, err := openslosdk.Decode(strings.NewReader(tc.input), openslosdk.FormatYAML)
+			require.NoError(t, err)
+
+			ruleFile, warnings, err := prometheus.Generate(objects)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantWarnings, warnings)
+			var out bytes.Buffer
+			require.NoError(t, ruleFile.Encode(&out))
+			assert.Equal(t, tc.wantOut, out.String())
+		})
+	}
+}
+
+func TestGenerate_ConvertsToV1(t *testing.T) {
+	t.Parallel()
+	input := `- apiVersion: openslo/v1alpha
+  kind: SLO
+  metadata:
+    name: api
+  spec:
+    service: web
+    budgetingMethod: Occurrences
+    timeWindows:
+      - unit: Month
+        count: 1
+        isRolling: false
+        calendar:
+          startTime: "2024-01-01 00:00:00"
+          timeZone: UTC
+    indicator:
+      thresholdMetric:
+        source: Prometheus
+        queryType: promql
+        query: web_latency_seconds
+    objectives:
+      - displayName: fast
+        op: lt
+        value: 0.2
+        target: 0.99
+`
+	objects, err := openslosdk.Decode(strings.NewReader(input), openslosdk.FormatYAML)
+	require.NoError(t, err)
+
+	ruleFile, warnings, err := prometheus.Generate(objects)
+	require.NoError(t, err)
+	assert.Equal(t, []prometheus.Warning{
+		{SLO: "api", Message: "calendar time window is approximated by a rolling window of 30d"},
+	}, warnings)
+	require.Len(t, ruleFile.Groups, 3)
+	assert.Equal(t, "openslo-api-fast-sli-recordings", ruleFile.Groups[0].Name)
+	assert.Equal(t, prometheus.Rule{
+		Record: "openslo:sli_error:ratio_rate5m",
+		Expr:   "1 - avg(avg_over_time(((web_latency_seconds) < bool 0.2)[5m:]))",
+		Labels: map[string]string{
+			"openslo_slo":       "api",
+			"openslo_service":   "web",
+			"openslo_objective": "fast",
+		},
+	}, ruleFile.Groups[0].Rules[0])
+}
@@ -0,0 +1,192 @@
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/OpenSLO/go-sdk/pkg/openslo/v1"
+)
+
+// metricSpecQueryKey is the metric source spec key holding the PromQL query.
+const metricSpecQueryKey = "query"
+
+var (
+	// windowPlaceholder is replaced with the evaluated window in queries which need full control over it,
+	// for example: sum(rate(http_requests_total{code=~"5.."}[{{.window}}])).
+	windowPlaceholder = regexp.MustCompile(`\{\{\s*\.window\s*\}\}`)
+	// vectorSelector matches plain instant vector selectors, for example: http_requests_total{code="200"}.
+	vectorSelector = regexp.MustCompile(`^(?:[a-zA-Z_:][a-zA-Z0-9_:]*\s*(?:\{[^{}]*\})?|\{[^{}]*\})$`)
+)
+
+var promQLOperators = map[v1.Operator]string{
+	v1.OperatorGT:  ">",
+	v1.OperatorGTE: ">=",
+	v1.OperatorLT:  "<",
+	v1.OperatorLTE: "<=",
+}
+
+// windowedQuery renders a query evaluated over the given window.
+type windowedQuery func(window time.Duration) string
+
+// errorRatioQuery builds the query returning the ratio of bad events to all events
+// of the indicator with respect to the objective.
+func errorRatioQuery(indicator v1.SLOIndicatorInline, objective v1.SLOObjective) (windowedQuery, error) {
+	spec := indicator.Spec
+	switch {
+	case spec.ThresholdMetric != nil:
+		return thresholdErrorRatioQuery(*spec.ThresholdMetric, objective)
+	case spec.RatioMetric != nil:
+		return ratioErrorRatioQuery(*spec.RatioMetric)
+	default:
+		return nil, errors.New("indicator has neither ratio nor threshold metric")
+	}
+}
+
+func ratioErrorRatioQuery(ratio v1.SLIRatioMetric) (windowedQuery, error) {
+	aggregate := func(metric *v1.SLIMetricSpec, name string) (windowedQuery, error) {
+		query, err := metricQuery(metric, name)
+		if err != nil {
+			return nil, err
+		}
+		return windowedAggregation(query, name, ratio.Counter, "sum")
+	}
+	switch {
+	case ratio.Raw != nil:
+		raw, err := metricQuery(ratio.Raw, "raw")
+		if err != nil {
+			return nil, err
+		}
+		rawQuery, err := windowedAggregation(raw, "raw", ratio.Counter, "avg")
+		if err != nil {
+			return nil, err
+		}
+		if ratio.RawType == v1.SLIRawMetricTypeFailure {
+			return rawQuery, nil
+		}
+		return func(window time.Duration) string {
+			return fmt.Sprintf("1 - (%s)", rawQuery(window))
+		}, nil
+	case ratio.Bad != nil:
+		bad, err := aggregate(ratio.Bad, "bad")
+		if err != nil {
+			return nil, err
+		}
+		total, err := aggregate(ratio.Total, "total")
+		if err != nil {
+			return nil, err
+		}
+		return func(window time.Duration) string {
+			return fmt.Sprintf("(%s) / (%s)", bad(window), total(window))
+		}, nil
+	default:
+		good, err := aggregate(ratio.Good, "good")
+		if err != nil {
+			return nil, err
+		}
+		total, err := aggregate(ratio.Total, "total")
+		if err != nil {
+			return nil, err
+		}
+		return func(window time.Duration) string {
+			return fmt.Sprintf("1 - ((%s) / (%s))", good(window), total(window))
+		}, nil
+	}
+}
+
+// thresholdErrorRatioQuery returns the ratio of samples which do not meet the objective's threshold.
+func thresholdErrorRatioQuery(metric v1.SLIMetricSpec, objective v1.SLOObjective) (windowedQuery, error) {
+	query, err := metricQuery(&metric, "threshold")
+	if err != nil {
+		return nil, err
+	}
+	if windowPlaceholder.MatchString(query) {
+		return nil, errors.New("threshold metric query must not use the {{.window}} placeholder")
+	}
+	operator, ok := promQLOperators[objective.Operator]
+	if !ok || objective.Value == nil {
+		return nil, errors.New("threshold metric requires objective op and value")
+	}
+	return func(window time.Duration) string {
+		return fmt.Sprintf("1 - avg(avg_over_time(((%s) %s bool %s)[%s:]))",
+			query, operator, formatFloat(*objective.Value), formatDuration(window))
+	}, nil
+}
+
+// windowedAggregation evaluates the query over a window and aggregates the resulting series.
+// Counters are evaluated with rate and gauges are averaged over the window.
+// Queries using the {{.window}} placeholder are only rendered, they are expected to aggregate on their own.
+func windowedAggregation(query, name string, counter bool, aggregation string) (windowedQuery, error) {
+	switch {
+	case windowPlaceholder.MatchString(query):
+		return func(window time.Duration) string {
+			return windowPlaceholder.ReplaceAllLiteralString(query, formatDuration(window))
+		}, nil
+	case vectorSelector.MatchString(query):
+		function := "avg_over_time"
+		if counter {
+			function = "rate"
+		}
+		return func(window time.Duration) string {
+			return fmt.Sprintf("%s(%s(%s[%s]))", aggregation, function, query, formatDuration(window))
+		}, nil
+	case counter:
+		return nil, fmt.Errorf(
+			"%s metric query must be a vector selector or use the {{.window}} placeholder for counters", name)
+	default:
+		return func(window time.Duration) string {
+			return fmt.Sprintf("%s(avg_over_time((%s)[%s:]))", aggregation, query, formatDuration(window))
+		}, nil
+	}
+}
+
+func metricQuery(metric *v1.SLIMetricSpec, name string) (string, error) {
+	if metric == nil {
+		return "", fmt.Errorf("%s metric is required", name)
+	}
+	query, _ := metric.MetricSource.Spec[metricSpecQueryKey].(string)
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "", fmt.Errorf("%s metric source spec must contain a '%s'", name, metricSpecQueryKey)
+	}
+	return query, nil
+}
+
+// formatDuration formats the duration using the largest Prometheus unit it is a multiple of.
+func formatDuration(d time.Duration) string {
+	units := []struct {
+		suffix string
+		unit   time.Duration
+	}{
+		{"w", 7 * 24 * time.Hour},
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	}
+	for _, u := range units {
+		if d >= u.unit && d%u.unit == 0 {
+			return fmt.Sprintf("%d%s", d/u.unit, u.suffix)
+		}
+	}
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
+
+// formatFloat formats the number without floating point noise, e.g. 1 - 0.999 is formatted as 0.001.
+func formatFloat(v float64) string {
+	const precision = 1e10
+	return strconv.FormatFloat(math.Round(v*precision)/precision, 'f', -1, 64)
+}
+
+// labelSelector renders a PromQL selector matching all the labels.
+func labelSelector(labels map[string]string, names ...string) string {
+	matchers := make([]string, 0, len(names))
+	for _, name := range names {
+		matchers = append(matchers, name+"="+strconv.Quote(labels[name]))
+	}
+	return "{" + strings.Join(matchers, ", ") + "}"
+}
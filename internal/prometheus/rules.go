@@ -0,0 +1,269 @@
+package prometheus
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	v1 "github.com/OpenSLO/go-sdk/pkg/openslo/v1"
+)
+
+// burnRateAlert fires when the error budget burn rate exceeds the factor over both the long and the short window.
+type burnRateAlert struct {
+	policy      string
+	condition   string
+	severity    string
+	description string
+	operator    string
+	factor      float64
+	long        time.Duration
+	short       time.Duration
+	alertAfter  time.Duration
+}
+
+// defaultBurnRateAlert describes the share of the error budget which may be consumed within the long window.
+type defaultBurnRateAlert struct {
+	severity string
+	consumed float64
+	long     time.Duration
+	short    time.Duration
+}
+
+// defaultBurnRateAlerts are used for SLOs without AlertPolicies.
+var defaultBurnRateAlerts = []defaultBurnRateAlert{
+	{severity: "page", consumed: 0.02, long: time.Hour, short: 5 * time.Minute},
+	{severity: "page", consumed: 0.05, long: 6 * time.Hour, short: 30 * time.Minute},
+	{severity: "ticket", consumed: 0.1, long: 24 * time.Hour, short: 2 * time.Hour},
+	{severity: "ticket", consumed: 0.1, long: 3 * 24 * time.Hour, short: 6 * time.Hour},
+}
+
+// noDataAlert fires when the SLI has not been recorded, it is added for AlertPolicies with alertWhenNoData.
+type noDataAlert struct {
+	policy   string
+	severity string
+}
+
+// burnRateAlerts builds the alerts from the SLO's AlertPolicies' conditions,
+// or from [defaultBurnRateAlerts] which fit within the SLO period if it has no AlertPolicies.
+func (g *generator) burnRateAlerts(slo v1.SLO, period time.Duration) []burnRateAlert {
+	if len(slo.Spec.AlertPolicies) == 0 {
+		alerts := make([]burnRateAlert, 0, len(defaultBurnRateAlerts))
+		for _, alert := range defaultBurnRateAlerts {
+			if alert.long > period {
+				continue
+			}
+			alerts = append(alerts, burnRateAlert{
+				severity: alert.severity,
+				operator: promQLOperators[v1.OperatorGT],
+				factor:   alert.consumed * float64(period) / float64(alert.long),
+				long:     alert.long,
+				short:    alert.short,
+			})
+		}
+		return alerts
+	}
+	var alerts []burnRateAlert
+	for i, policy := range slo.Spec.AlertPolicies {
+		if policy.SLOAlertPolicyInline == nil {
+			continue
+		}
+		for j, condition := range policy.Spec.Conditions {
+			if condition.AlertPolicyConditionInline == nil {
+				continue
+			}
+			path := fmt.Sprintf("spec.alertPolicies[%d].spec.conditions[%d]", i, j)
+			spec := condition.Spec
+			if spec.Condition.Kind != v1.AlertConditionKindBurnRate || spec.Condition.Threshold == nil {
+				g.warn(slo, "%s: skipped, only burnrate conditions with a threshold are supported", path)
+				continue
+			}
+			long := spec.Condition.LookbackWindow.Duration()
+			alert := burnRateAlert{
+				policy:      policy.Metadata.Name,
+				condition:   condition.Metadata.Name,
+				severity:    spec.Severity,
+				description: spec.Description,
+				operator:    promQLOperators[spec.Condition.Operator],
+				factor:      *spec.Condition.Threshold,
+				long:        long,
+				short:       long / shortWindowDivisor,
+			}
+			if spec.Condition.AlertAfter != nil {
+				alert.alertAfter = spec.Condition.AlertAfter.Duration()
+			}
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts
+}
+
+func noDataAlerts(slo v1.SLO) []noDataAlert {
+	var alerts []noDataAlert
+	for _, policy := range slo.Spec.AlertPolicies {
+		if policy.SLOAlertPolicyInline == nil || !policy.Spec.AlertWhenNoData {
+			continue
+		}
+		alert := noDataAlert{policy: policy.Metadata.Name}
+		for _, condition := range policy.Spec.Conditions {
+			if condition.AlertPolicyConditionInline != nil {
+				alert.severity = condition.Spec.Severity
+				break
+			}
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
+
+// objectiveRules generates the rule groups of a single SLO objective.
+type objectiveRules struct {
+	slo          v1.SLO
+	name         string
+	target       float64
+	period       time.Duration
+	errorRatio   windowedQuery
+	alerts       []burnRateAlert
+	noDataAlerts []noDataAlert
+}
+
+func (o objectiveRules) groups() []RuleGroup {
+	prefix := fmt.Sprintf("openslo-%s-%s", o.slo.Metadata.Name, o.name)
+	groups := []RuleGroup{
+		{Name: prefix + "-sli-recordings", Rules: o.sliRecordings()},
+		{Name: prefix + "-meta-recordings", Rules: o.metaRecordings()},
+	}
+	if alerts := o.alertRules(); len(alerts) > 0 {
+		groups = append(groups, RuleGroup{Name: prefix + "-alerts", Rules: alerts})
+	}
+	return groups
+}
+
+// sliRecordings records the error ratio over the base window and every alert window.
+// The error ratio over the SLO period is averaged from the base window recording by [objectiveRules.metaRecordings].
+func (o objectiveRules) sliRecordings() []Rule {
+	windows := []time.Duration{baseWindow}
+	for _, alert := range o.alerts {
+		windows = append(windows, alert.long, alert.short)
+	}
+	slices.Sort(windows)
+	windows = slices.Compact(windows)
+	rules := make([]Rule, 0, len(windows))
+	for _, window := range windows {
+		if window == o.period && window != baseWindow {
+			continue
+		}
+		rules = append(rules, Rule{
+			Record: errorRatioMetric(window),
+			Expr:   o.errorRatio(window),
+			Labels: o.labels(),
+		})
+	}
+	return rules
+}
+
+func (o objectiveRules) metaRecordings() []Rule {
+	selector := o.selector()
+	budget := formatFloat(1 - o.target)
+	rules := []Rule{
+		{
+			Record: MetricObjective,
+			Expr:   fmt.Sprintf("vector(%s)", formatFloat(o.target)),
+			Labels: o.labels(),
+		},
+		{
+			Record: MetricErrorBudget,
+			Expr:   fmt.Sprintf("vector(%s)", budget),
+			Labels: o.labels(),
+		},
+	}
+	if o.period != baseWindow {
+		rules = append(rules, Rule{
+			Record: errorRatioMetric(o.period),
+			Expr: fmt.Sprintf("avg_over_time(%s%s[%s])",
+				errorRatioMetric(baseWindow), selector, formatDuration(o.period)),
+			Labels: o.labels(),
+		})
+	}
+	return append(rules, Rule{
+		Record: MetricErrorBudgetRemaining,
+		Expr:   fmt.Sprintf("1 - (%s%s / %s)", errorRatioMetric(o.period), selector, budget),
+		Labels: o.labels(),
+	})
+}
+
+func (o objectiveRules) alertRules() []Rule {
+	selector := o.selector()
+	budget := formatFloat(1 - o.target)
+	rules := make([]Rule, 0, len(o.alerts)+len(o.noDataAlerts))
+	for _, alert := range o.alerts {
+		threshold := fmt.Sprintf("%s (%s * %s)", alert.operator, formatFloat(alert.factor), budget)
+		labels := o.labels()
+		labels[LabelLongWindow] = formatDuration(alert.long)
+		labels[LabelShortWindow] = formatDuration(alert.short)
+		setNonEmpty(labels, LabelSeverity, alert.severity)
+		setNonEmpty(labels, LabelAlertPolicy, alert.policy)
+		setNonEmpty(labels, LabelAlertCondition, alert.condition)
+		description := alert.description
+		if description == "" {
+			description = fmt.Sprintf("Error budget burn rate is %s %s over the last %s and %s.",
+				alert.operator, formatFloat(alert.factor), formatDuration(alert.long), formatDuration(alert.short))
+		}
+		rule := Rule{
+			Alert: AlertErrorBudgetBurn,
+			Expr: fmt.Sprintf("%s%s %s and %s%s %s",
+				errorRatioMetric(alert.long), selector, threshold,
+				errorRatioMetric(alert.short), selector, threshold),
+			Labels: labels,
+			Annotations: map[string]string{
+				"summary":     o.summary("is burning its error budget too fast"),
+				"description": description,
+			},
+		}
+		if alert.alertAfter > 0 {
+			rule.For = formatDuration(alert.alertAfter)
+		}
+		rules = append(rules, rule)
+	}
+	for _, alert := range o.noDataAlerts {
+		labels := o.labels()
+		setNonEmpty(labels, LabelSeverity, alert.severity)
+		setNonEmpty(labels, LabelAlertPolicy, alert.policy)
+		rules = append(rules, Rule{
+			Alert:  AlertNoData,
+			Expr:   fmt.Sprintf("absent(%s%s)", errorRatioMetric(baseWindow), selector),
+			For:    formatDuration(noDataAlertFor),
+			Labels: labels,
+			Annotations: map[string]string{
+				"summary": o.summary("has no data"),
+			},
+		})
+	}
+	return rules
+}
+
+func (o objectiveRules) labels() map[string]string {
+	return map[string]string{
+		LabelSLO:       o.slo.Metadata.Name,
+		LabelService:   o.slo.Spec.Service,
+		LabelObjective: o.name,
+	}
+}
+
+func (o objectiveRules) selector() string {
+	return labelSelector(o.labels(), LabelSLO, LabelService, LabelObjective)
+}
+
+func (o objectiveRules) summary(state string) string {
+	return fmt.Sprintf("SLO '%s' objective '%s' of service '%s' %s.",
+		o.slo.Metadata.Name, o.name, o.slo.Spec.Service, state)
+}
+
+func errorRatioMetric(window time.Duration) string {
+	return MetricSLIErrorRatio + formatDuration(window)
+}
+
+func setNonEmpty(labels map[string]string, name, value string) {
+	if value != "" {
+		labels[name] = value
+	}
+}
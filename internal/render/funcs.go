@@ -0,0 +1,177 @@
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+)
+
+// funcMap returns helpers available in templates.
+// Their names and semantics follow the sprig library used by Helm, so that templates are familiar.
+func funcMap() template.FuncMap {
+	return template.FuncMap{
+		// Defaults and flow control.
+		"default":  defaultValue,
+		"empty":    isEmpty,
+		"coalesce": coalesce,
+		"ternary":  ternary,
+		"required": required,
+		"fail":     func(msg string) (string, error) { return "", errors.New(msg) },
+		// Strings.
+		"quote":      func(v any) string { return fmt.Sprintf("%q", toString(v)) },
+		"squote":     func(v any) string { return "'" + toString(v) + "'" },
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":    func(old, replacement, s string) string { return strings.ReplaceAll(s, old, replacement) },
+		"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"repeat":     func(count int, s string) string { return strings.Repeat(s, count) },
+		"indent":     indent,
+		"nindent":    func(spaces int, s string) string { return "\n" + indent(spaces, s) },
+		"toString":   toString,
+		// Lists and dictionaries.
+		"list":  func(items ...any) []any { return items },
+		"dict":  dict,
+		"join":  join,
+		"split": func(sep, s string) []string { return strings.Split(s, sep) },
+		"keys":  keys,
+		"has":   has,
+		// Encoding.
+		"toYaml": toYAML,
+		"toJson": toJSON,
+	}
+}
+
+// defaultValue returns the given value, unless it's empty, in which case the default is returned.
+// It's used as {{ .Values.env | default "prod" }}.
+func defaultValue(def any, given ...any) any {
+	if len(given) == 0 || isEmpty(given[0]) {
+		return def
+	}
+	return given[0]
+}
+
+func coalesce(values ...any) any {
+	for _, v := range values {
+		if !isEmpty(v) {
+			return v
+		}
+	}
+	return nil
+}
+
+func ternary(whenTrue, whenFalse any, condition bool) any {
+	if condition {
+		return whenTrue
+	}
+	return whenFalse
+}
+
+func required(msg string, v any) (any, error) {
+	if isEmpty(v) {
+		return nil, errors.New(msg)
+	}
+	return v, nil
+}
+
+// isEmpty reports whether the value is nil or the zero value of its type, including empty collections.
+func isEmpty(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return rv.IsZero()
+	}
+}
+
+func toString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+func dict(pairs ...any) (map[string]any, error) {
+	if len(pairs)%2 != 0 {
+		return nil, errors.New("dict requires an even number of arguments")
+	}
+	d := make(map[string]any, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		d[toString(pairs[i])] = pairs[i+1]
+	}
+	return d, nil
+}
+
+func join(sep string, v any) string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return toString(v)
+	}
+	items := make([]string, 0, rv.Len())
+	for i := range rv.Len() {
+		items = append(items, toString(rv.Index(i).Interface()))
+	}
+	return strings.Join(items, sep)
+}
+
+// keys returns sorted keys of the map, the same order range action iterates over maps.
+func keys(m map[string]any) []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+func has(needle, haystack any) bool {
+	rv := reflect.ValueOf(haystack)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return false
+	}
+	for i := range rv.Len() {
+		if reflect.DeepEqual(rv.Index(i).Interface(), needle) {
+			return true
+		}
+	}
+	return false
+}
+
+func toYAML(v any) (string, error) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+func toJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
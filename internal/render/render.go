@@ -0,0 +1,70 @@
+// Package render renders manifests written as Go templates, similarly to Helm charts.
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Data is the data passed to templates.
+type Data struct {
+	// Values are merged from all values files.
+	Values map[string]any
+}
+
+// Render executes the template with the provided values.
+// The name should be the path of the template, it's used in error messages
+// alongside the line and column, e.g. template: slos/api.yaml:3:12: ...
+func Render(name string, content []byte, values map[string]any) ([]byte, error) {
+	if values == nil {
+		values = map[string]any{}
+	}
+	tpl, err := template.New(name).
+		Option("missingkey=default").
+		Funcs(funcMap()).
+		Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err = tpl.Execute(&buf, Data{Values: values}); err != nil {
+		return nil, err
+	}
+	// Missing values are rendered as empty strings, the same way Helm does it.
+	return bytes.ReplaceAll(buf.Bytes(), []byte("<no value>"), nil), nil
+}
+
+// LoadValues reads and merges the values files.
+// Files are merged in order, maps are merged recursively and other values from later files win.
+func LoadValues(paths []string) (map[string]any, error) {
+	values := make(map[string]any)
+	for _, path := range paths {
+		data, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read values file: %w", err)
+		}
+		var fileValues map[string]any
+		if err = yaml.Unmarshal(data, &fileValues); err != nil {
+			return nil, fmt.Errorf("failed to decode values file %s: %w", path, err)
+		}
+		mergeValues(values, fileValues)
+	}
+	return values, nil
+}
+
+func mergeValues(dst, src map[string]any) {
+	for k, v := range src {
+		srcMap, srcIsMap := v.(map[string]any)
+		dstMap, dstIsMap := dst[k].(map[string]any)
+		if srcIsMap && dstIsMap {
+			mergeValues(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+}
@@ -0,0 +1,102 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+	values := map[string]any{
+		"env":      "prod",
+		"clusters": []any{"eu-1", "us-1"},
+		"labels":   map[string]any{"team": "platform", "tier": "1"},
+		"target":   0.999,
+	}
+
+	tests := map[string]struct {
+		template string
+		out      string
+		wantErr  string
+	}{
+		"values": {
+			template: "name: api-{{ .Values.env }}\ntarget: {{ .Values.target }}\n",
+			out:      "name: api-prod\ntarget: 0.999\n",
+		},
+		"missing value is empty": {
+			template: "name: api{{ .Values.missing }}",
+			out:      "name: api",
+		},
+		"default": {
+			template: `{{ .Values.missing | default "staging" }} {{ .Values.env | default "staging" }}`,
+			out:      "staging prod",
+		},
+		"string helpers": {
+			template: `{{ .Values.env | upper | quote }} {{ "  x " | trim | squote }} {{ replace "-" "_" "a-b" }}`,
+			out:      `"PROD" 'x' a_b`,
+		},
+		"lists": {
+			template: `{{ join "," .Values.clusters }} {{ has "eu-1" .Values.clusters }} {{ list 1 2 | toJson }}`,
+			out:      `eu-1,us-1 true [1,2]`,
+		},
+		"toYaml with nindent": {
+			template: "labels:{{ .Values.labels | toYaml | nindent 2 }}",
+			out:      "labels:\n  team: platform\n  tier: \"1\"",
+		},
+		"range over keys": {
+			template: `{{ range keys .Values.labels }}{{ . }};{{ end }}`,
+			out:      "team;tier;",
+		},
+		"dict and ternary": {
+			template: `{{ (dict "a" 1).a }} {{ ternary "yes" "no" (eq .Values.env "prod") }}`,
+			out:      "1 yes",
+		},
+		"required": {
+			template: "kind: SLO\nname: {{ required \"service is required\" .Values.service }}",
+			wantErr:  `template: slos/api.yaml:2:9: executing "slos/api.yaml" at <required "service is required" .Values.service>: error calling required: service is required`,
+		},
+		"parse error": {
+			template: "kind: SLO\n\nname: {{ .Values.env | unknown }}",
+			wantErr:  `template: slos/api.yaml:3: function "unknown" not defined`,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			out, err := Render("slos/api.yaml", []byte(tc.template), values)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.out, string(out))
+		})
+	}
+}
+
+func TestLoadValues(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+	base := write("values.yaml", "env: staging\nlabels:\n  team: platform\n  tier: \"2\"\nclusters: [eu-1, us-1]\n")
+	prod := write("values-prod.yaml", "env: prod\nlabels:\n  tier: \"1\"\nclusters: [eu-1]\n")
+
+	values, err := LoadValues([]string{base, prod})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"env":      "prod",
+		"labels":   map[string]any{"team": "platform", "tier": "1"},
+		"clusters": []any{"eu-1"},
+	}, values)
+
+	_, err = LoadValues([]string{write("invalid.yaml", "- a list")})
+	require.ErrorContains(t, err, "failed to decode values file")
+}
@@ -0,0 +1,210 @@
+// Package schema exports definitions of OpenSLO objects in formats understood by other tools.
+package schema
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/token"
+	"github.com/OpenSLO/go-sdk/pkg/openslo"
+	v1 "github.com/OpenSLO/go-sdk/pkg/openslo/v1"
+	"github.com/OpenSLO/go-sdk/pkg/openslo/v1alpha"
+	"github.com/OpenSLO/go-sdk/pkg/openslo/v2alpha"
+)
+
+// objectDefinition binds the kind of an object to its Go type.
+type objectDefinition struct {
+	kind openslo.Kind
+	typ  any
+}
+
+// objectDefinitions lists all objects of each supported version.
+var objectDefinitions = map[openslo.Version][]objectDefinition{
+	openslo.VersionV1alpha: {
+		{openslo.KindService, v1alpha.Service{}},
+		{openslo.KindSLO, v1alpha.SLO{}},
+	},
+	openslo.VersionV1: {
+		{openslo.KindDataSource, v1.DataSource{}},
+		{openslo.KindService, v1.Service{}},
+		{openslo.KindAlertNotificationTarget, v1.AlertNotificationTarget{}},
+		{openslo.KindAlertCondition, v1.AlertCondition{}},
+		{openslo.KindAlertPolicy, v1.AlertPolicy{}},
+		{openslo.KindSLI, v1.SLI{}},
+		{openslo.KindSLO, v1.SLO{}},
+	},
+	openslo.VersionV2alpha: {
+		{openslo.KindDataSource, v2alpha.DataSource{}},
+		{openslo.KindService, v2alpha.Service{}},
+		{openslo.KindAlertNotificationTarget, v2alpha.AlertNotificationTarget{}},
+		{openslo.KindAlertCondition, v2alpha.AlertCondition{}},
+		{openslo.KindAlertPolicy, v2alpha.AlertPolicy{}},
+		{openslo.KindSLI, v2alpha.SLI{}},
+		{openslo.KindSLO, v2alpha.SLO{}},
+	},
+}
+
+// durationPatterns are the formats of DurationShorthand of each version which has it.
+var durationPatterns = map[openslo.Version]string{
+	openslo.VersionV1:      `^[0-9]+[mhdwMQY]$`,
+	openslo.VersionV2alpha: `^[0-9]+[mhdw]$`,
+}
+
+var durationTypes = []reflect.Type{
+	reflect.TypeFor[v1.DurationShorthand](),
+	reflect.TypeFor[v2alpha.DurationShorthand](),
+}
+
+// durationDefinition is the name of the definition durations are constrained with.
+const durationDefinition = "#Duration"
+
+// CUE returns a CUE file declaring the package with a definition of every object of the version,
+// e.g. #SLO, and an #Object definition which is a disjunction of all of them.
+// The definitions are derived from the Go types of the OpenSLO SDK.
+func CUE(version openslo.Version, pkg string) ([]byte, error) {
+	definitions, ok := objectDefinitions[version]
+	if !ok {
+		return nil, fmt.Errorf("unsupported version: %s", version)
+	}
+	ctx := cuecontext.New()
+	file := &ast.File{}
+	file.Decls = append(file.Decls, &ast.Package{Name: ast.NewIdent(pkg)})
+	ast.AddComment(file.Decls[0], &ast.CommentGroup{
+		Doc:  true,
+		List: []*ast.Comment{{Text: fmt.Sprintf("// Code generated by oslo schema cue for %s. DO NOT EDIT.", version)}},
+	})
+	objectRefs := make([]ast.Expr, 0, len(definitions))
+	for _, def := range definitions {
+		header := ctx.CompileString(fmt.Sprintf("{apiVersion: %q, kind: %q}", version, def.kind))
+		value := ctx.EncodeType(def.typ).Unify(header)
+		if err := value.Err(); err != nil {
+			return nil, fmt.Errorf("failed to encode %s %s: %w", version, def.kind, err)
+		}
+		expr, ok := value.Syntax(cue.Optional(true), cue.Definitions(true)).(ast.Expr)
+		if !ok {
+			return nil, fmt.Errorf("failed to encode %s %s: unexpected syntax", version, def.kind)
+		}
+		expr = rewriteSyntax(expr, durationFields(reflect.TypeOf(def.typ), make(map[reflect.Type]bool)))
+		name := "#" + def.kind.String()
+		file.Decls = append(file.Decls, &ast.Field{Label: ast.NewIdent(name), Value: expr})
+		objectRefs = append(objectRefs, ast.NewIdent(name))
+	}
+	file.Decls = append(file.Decls, &ast.Field{
+		Label: ast.NewIdent("#Object"),
+		Value: ast.NewBinExpr(token.OR, objectRefs...),
+	})
+	if pattern, ok := durationPatterns[version]; ok {
+		file.Decls = append(file.Decls, &ast.Field{
+			Label: ast.NewIdent(durationDefinition),
+			Value: ast.NewBinExpr(token.AND,
+				ast.NewIdent("string"),
+				&ast.UnaryExpr{Op: token.MAT, X: ast.NewString(pattern)}),
+		})
+	}
+	return format.Node(file)
+}
+
+// rewriteSyntax fixes the parts of the encoded Go types which CUE cannot express on its own:
+//   - structs embedding a reference and an inline object by pointer, e.g. v1.AlertPolicyCondition,
+//     are encoded as a single field with an empty label and a conjunction of both structs,
+//     they are replaced with a disjunction of the reference and the inline object,
+//   - DurationShorthand fields, which are encoded as top, are constrained with [durationDefinition].
+func rewriteSyntax(expr ast.Expr, durations map[string]bool) ast.Expr {
+	return astutil.Apply(expr, nil, func(c astutil.Cursor) bool {
+		switch n := c.Node().(type) {
+		case *ast.StructLit:
+			if alternatives := embeddedAlternatives(n); len(alternatives) > 0 {
+				c.Replace(&ast.ParenExpr{X: ast.NewBinExpr(token.OR, alternatives...)})
+			}
+		case *ast.Field:
+			name, _, err := ast.LabelName(n.Label)
+			if ident, ok := n.Value.(*ast.Ident); err == nil && ok && ident.Name == "_" && durations[name] {
+				n.Value = ast.NewIdent(durationDefinition)
+			}
+		}
+		return true
+	}).(ast.Expr)
+}
+
+// embeddedAlternatives returns the structs of `{""?: (*null | {...}) & (*null | {...})}`,
+// which is how [cue.Context.EncodeType] encodes a struct embedding other structs by pointer.
+func embeddedAlternatives(s *ast.StructLit) []ast.Expr {
+	if len(s.Elts) != 1 {
+		return nil
+	}
+	field, ok := s.Elts[0].(*ast.Field)
+	if !ok {
+		return nil
+	}
+	if label, ok := field.Label.(*ast.BasicLit); !ok || label.Value != `""` {
+		return nil
+	}
+	var alternatives []ast.Expr
+	conjunction := field.Value
+	for {
+		binary, ok := conjunction.(*ast.BinaryExpr)
+		if !ok || binary.Op != token.AND {
+			break
+		}
+		alternatives = append(alternatives, nonNullAlternative(binary.Y))
+		conjunction = binary.X
+	}
+	alternatives = append(alternatives, nonNullAlternative(conjunction))
+	if slices.Contains(alternatives, nil) {
+		return nil
+	}
+	slices.Reverse(alternatives)
+	return alternatives
+}
+
+// nonNullAlternative returns X of `(*null | X)`, or nil if the expression has a different form.
+func nonNullAlternative(expr ast.Expr) ast.Expr {
+	if paren, ok := expr.(*ast.ParenExpr); ok {
+		expr = paren.X
+	}
+	binary, ok := expr.(*ast.BinaryExpr)
+	if !ok || binary.Op != token.OR {
+		return nil
+	}
+	if _, ok = binary.X.(*ast.UnaryExpr); !ok {
+		return nil
+	}
+	return binary.Y
+}
+
+// durationFields returns the JSON names of all DurationShorthand fields found in the Go type.
+func durationFields(typ reflect.Type, visited map[reflect.Type]bool) map[string]bool {
+	fields := make(map[string]bool)
+	for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Map {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || visited[typ] {
+		return fields
+	}
+	visited[typ] = true
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if slices.Contains(durationTypes, fieldType) {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			fields[name] = true
+			continue
+		}
+		maps.Copy(fields, durationFields(field.Type, visited))
+	}
+	return fields
+}
@@ -0,0 +1,95 @@
+package schema
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"github.com/OpenSLO/go-sdk/pkg/openslo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func TestCUE(t *testing.T) {
+	t.Parallel()
+	tests := map[openslo.Version][]string{
+		openslo.VersionV1alpha: {"../../test/inputs/validate/v1alpha.yaml"},
+		openslo.VersionV1:      {"../../test/inputs/validate/v1.yaml", "testdata/references-v1.yaml"},
+		openslo.VersionV2alpha: {"../../test/inputs/validate/v2alpha.yaml", "testdata/references-v2alpha.yaml"},
+	}
+	for version, examplesPaths := range tests {
+		t.Run(version.String(), func(t *testing.T) {
+			t.Parallel()
+			data, err := CUE(version, "openslo")
+			require.NoError(t, err)
+			ctx := cuecontext.New()
+			schema := ctx.CompileBytes(data)
+			require.NoError(t, schema.Err())
+			object := schema.LookupPath(cue.ParsePath("#Object"))
+			require.True(t, object.Exists())
+
+			for _, examplesPath := range examplesPaths {
+				examples := readExamples(t, examplesPath)
+				require.NotEmpty(t, examples)
+				for i, example := range examples {
+					value := object.Unify(ctx.Encode(example))
+					assert.NoError(t, value.Validate(cue.Concrete(true)), "%s: example %d", examplesPath, i)
+				}
+			}
+
+			invalid := map[string]any{
+				"apiVersion": version.String(),
+				"kind":       "Service",
+				"metadata":   map[string]any{"name": 1},
+			}
+			assert.Error(t, object.Unify(ctx.Encode(invalid)).Validate(cue.Concrete(true)))
+		})
+	}
+
+	t.Run("invalid duration", func(t *testing.T) {
+		t.Parallel()
+		data, err := CUE(openslo.VersionV1, "openslo")
+		require.NoError(t, err)
+		ctx := cuecontext.New()
+		condition := ctx.CompileBytes(data).LookupPath(cue.ParsePath("#AlertCondition"))
+		require.True(t, condition.Exists())
+		example := map[string]any{
+			"apiVersion": "openslo/v1",
+			"kind":       "AlertCondition",
+			"metadata":   map[string]any{"name": "burn-rate"},
+			"spec": map[string]any{
+				"severity": "page",
+				"condition": map[string]any{
+					"kind":           "burnrate",
+					"op":             "gt",
+					"threshold":      2,
+					"lookbackWindow": "1h",
+				},
+			},
+		}
+		require.NoError(t, condition.Unify(ctx.Encode(example)).Validate(cue.Concrete(true)))
+		example["spec"].(map[string]any)["condition"].(map[string]any)["lookbackWindow"] = "1 hour"
+		assert.Error(t, condition.Unify(ctx.Encode(example)).Validate(cue.Concrete(true)))
+	})
+
+	_, err := CUE("openslo/v3", "openslo")
+	require.EqualError(t, err, "unsupported version: openslo/v3")
+}
+
+func readExamples(t *testing.T, path string) []any {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	jsonData, err := yaml.YAMLToJSON(data)
+	require.NoError(t, err)
+	var examples []any
+	if err = json.Unmarshal(jsonData, &examples); err != nil {
+		var example any
+		require.NoError(t, json.Unmarshal(jsonData, &example))
+		examples = []any{example}
+	}
+	return examples
+}
@@ -48,3 +48,9 @@ setup() {
   assert_failure
   assert_output "$(cat "${TEST_SUITE_OUTPUTS}/validate/recursive")"
 }
+
+@test "files passed as arguments are read once" {
+  run oslo validate "${TEST_SUITE_INPUTS}/validate/v1.yaml" -f "${TEST_SUITE_INPUTS}/validate/v1.yaml"
+  assert_failure
+  assert_output "$(cat "${TEST_SUITE_OUTPUTS}/validate/v1")"
+}